@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"os"
+)
+
+// runApply applies an overlay to a local OpenAPI document
+func runApply(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	overlayFlag := flags.String("overlay", "", "overlay file to apply")
+	outputFlag := flags.String("output", "", "file the result is written to, defaults to stdout")
+	inPlaceFlag := flags.Bool("in-place", false, "overwrite the document with the result")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: apply -overlay overlay.yaml [-output file | -in-place] openapi.yaml")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if len(*overlayFlag) == 0 || flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	content, err := os.ReadFile(*overlayFlag)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	overlay, err := doc.ParseOverlay(content)
+	if err != nil {
+		return fail(stderr, exitError, fmt.Errorf("%s: %w", *overlayFlag, err))
+	}
+
+	path := flags.Arg(0)
+	document, err := os.ReadFile(path)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	out, err := doc.ApplyOverlay(document, overlay)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+
+	output := *outputFlag
+	if *inPlaceFlag {
+		output = path
+	}
+	if err = writeOutput(output, out, stdout); err != nil {
+		return fail(stderr, exitError, err)
+	}
+
+	return exitOK
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"time"
+)
+
+// runCache inspects or prunes the cache of the generated schemas
+func runCache(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cache", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	cacheFlag := flags.String("cache", "", "cache directory, defaults to the cache of the config file")
+	configFlag := flags.String("config", "", "config file, discovered upwards from the working directory")
+	unusedFlag := flags.Duration("unused", 7*24*time.Hour, "prune removes the entries not used for this duration")
+	allFlag := flags.Bool("all", false, "prune removes all entries")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: cache inspect|prune [-cache dir] [-unused 168h] [-all]")
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || (args[0] != "inspect" && args[0] != "prune") {
+		flags.Usage()
+		return exitUsage
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+
+	dir := *cacheFlag
+	if len(dir) == 0 {
+		config, err := doc.LoadConfig(*configFlag)
+		if err != nil {
+			return fail(stderr, exitUsage, fmt.Errorf("no cache given: %w", err))
+		}
+		if len(config.Cache) == 0 {
+			return fail(stderr, exitUsage, errors.New("no cache given and the config has no cache"))
+		}
+		dir = config.Path(config.Cache)
+	}
+	cache := doc.NewCache(dir)
+
+	if args[0] == "inspect" {
+		entries, err := cache.Entries()
+		if err != nil {
+			return fail(stderr, exitError, err)
+		}
+		var size int64
+		for _, entry := range entries {
+			fmt.Fprintf(stdout, "%s %s %d schemas %d bytes, used %s\n", entry.Key[:12], entry.Package, entry.Schemas, entry.Size, entry.Used.Format(time.RFC3339))
+			size += entry.Size
+		}
+		fmt.Fprintf(stdout, "%s: %d entries, %d bytes\n", dir, len(entries), size)
+		return exitOK
+	}
+
+	unusedSince := time.Now().Add(-*unusedFlag)
+	if *allFlag {
+		unusedSince = time.Time{}
+	}
+	pruned, err := cache.Prune(unusedSince)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	for _, entry := range pruned {
+		fmt.Fprintf(stdout, "removed %s %s\n", entry.Key[:12], entry.Package)
+	}
+	fmt.Fprintf(stdout, "%s: removed %d entries\n", dir, len(pruned))
+
+	return exitOK
+}
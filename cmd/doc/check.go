@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"os"
+)
+
+// runCheck regenerates the targets in memory and reports the targets whose committed output file is out of date
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	targetFlags := newTargetFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	targets, code := targetFlags.targets(stderr)
+	if code != exitOK {
+		return code
+	}
+
+	result := exitOK
+	for _, t := range targets {
+		if len(t.output) == 0 {
+			return fail(stderr, exitUsage, errors.New("check requires an output file"))
+		}
+
+		changes, err := check(t)
+		if os.IsNotExist(err) {
+			result = exitDrift
+			fmt.Fprintf(stdout, "%s does not exist, generate it\n", t.output)
+			continue
+		} else if err != nil {
+			return fail(stderr, exitError, err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		result = exitDrift
+		fmt.Fprintf(stdout, "%s is out of date, regenerate it:\n", t.output)
+		for _, change := range changes {
+			fmt.Fprintf(stdout, "  %s\n", change)
+		}
+	}
+
+	return result
+}
+
+// check returns the changes between the committed output file of the target and the regenerated content
+func check(t target) ([]doc.Change, error) {
+	committed, err := os.ReadFile(t.output)
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := t.schemas()
+	if err != nil {
+		return nil, err
+	}
+
+	var generated []byte
+	if t.merge {
+		generated, _, err = doc.MergeSchemas(committed, specs, t.prune)
+	} else {
+		generated, err = t.render(specs, io.Discard)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := doc.CompareDocuments(committed, generated)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.output, err)
+	}
+
+	return changes, nil
+}
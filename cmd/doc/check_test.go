@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testDir returns a directory holding the generated document generated.yaml and the document mismatch.yaml
+// whose schema differs from the Go type
+func testDir(t *testing.T) string {
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	args := append([]string{"-output", filepath.Join(dir, "generated.yaml")}, testPackageFlags...)
+	assert.Equal(t, exitOK, run(args, &stdout, &stderr), stderr.String())
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "mismatch.yaml"), []byte(`openapi: 3.0.3
+info: {title: Test, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    TestOtherBaseStruct:
+      type: object
+      properties:
+        BaseFieldB: {type: integer}
+        BaseFieldC: {type: number, format: double}
+        BaseFieldD: {type: boolean}
+`), 0o644))
+
+	return dir
+}
+
+func Test_Run_Check(t *testing.T) {
+	dir := testDir(t)
+
+	testRun(t, []runCase{
+		{name: "up to date", args: append([]string{"check", "-output", filepath.Join(dir, "generated.yaml")}, testPackageFlags...), code: exitOK},
+		{name: "without output", args: append([]string{"check"}, testPackageFlags...), code: exitUsage, stderr: "check requires an output file"},
+		{name: "missing output", args: append([]string{"check", "-output", filepath.Join(dir, "missing.yaml")}, testPackageFlags...), code: exitDrift, stdout: "does not exist"},
+		{name: "out of date", args: append([]string{"check", "-output", filepath.Join(dir, "mismatch.yaml")}, testPackageFlags...), code: exitDrift, stdout: "is out of date"},
+	})
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"os"
+)
+
+// runDiff reports the changes between two versions of the models and exits with exitDrift on breaking changes.
+// The versions are either two OpenAPI documents or two source directories, e.g. git worktrees, generated
+// with the same flags or config.
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	targetFlags := newTargetFlags(flags)
+	oldFlag := flags.String("old", "", "OpenAPI document or source directory of the old version")
+	newFlag := flags.String("new", ".", "OpenAPI document or source directory of the new version")
+	reportFlag := flags.String("report", string(doc.TextReport), "report format text, json or markdown")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: diff -old old.yaml -new new.yaml | diff -old ../v1 [-new .] [generate flags] [packages]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if len(*oldFlag) == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+	format, err := doc.ParseReportFormat(*reportFlag)
+	if err != nil {
+		return fail(stderr, exitUsage, err)
+	}
+
+	var versions [2][]spec.Schema
+	for i, path := range []string{*oldFlag, *newFlag} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fail(stderr, exitUsage, err)
+		}
+		if !info.IsDir() {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fail(stderr, exitError, err)
+			}
+			if versions[i], err = doc.ParseDocumentSchemas(content); err != nil {
+				return fail(stderr, exitError, fmt.Errorf("%s: %w", path, err))
+			}
+			continue
+		}
+
+		targets, code := targetFlags.targetsIn(path, stderr)
+		if code != exitOK {
+			return code
+		}
+		registry := make(doc.SpecRegistry)
+		for _, t := range targets {
+			schemas, err := t.schemas()
+			if err != nil {
+				return fail(stderr, exitError, fmt.Errorf("%s: %w", path, err))
+			}
+			for _, schema := range schemas {
+				registry.AddSchema(doc.ComponentName(schema), schema)
+			}
+		}
+		if len(registry) == 0 {
+			return fail(stderr, exitError, errors.New(path+": no schemas generated"))
+		}
+		versions[i] = registry.Values()
+	}
+
+	report, err := doc.DiffSchemas(versions[0], versions[1])
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	if err = report.Write(stdout, format); err != nil {
+		return fail(stderr, exitError, err)
+	}
+	if report.HasBreaking() {
+		return exitDrift
+	}
+
+	return exitOK
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func Test_Run_Diff(t *testing.T) {
+	dir := testDir(t)
+	generated, mismatch := filepath.Join(dir, "generated.yaml"), filepath.Join(dir, "mismatch.yaml")
+
+	testRun(t, []runCase{
+		{name: "without old", args: []string{"diff"}, code: exitUsage, stderr: "usage: diff"},
+		{name: "missing old", args: []string{"diff", "-old", filepath.Join(dir, "missing.yaml"), "-new", generated}, code: exitUsage},
+		{name: "unknown report", args: []string{"diff", "-report", "xml", "-old", generated}, code: exitUsage, stderr: "unknown report format"},
+		{name: "unchanged", args: []string{"diff", "-old", generated, "-new", generated}, code: exitOK},
+		{name: "breaking", args: []string{"diff", "-old", generated, "-new", mismatch}, code: exitDrift, stdout: "BaseFieldB"},
+		{name: "markdown", args: []string{"diff", "-report", "markdown", "-old", generated, "-new", mismatch}, code: exitDrift, stdout: "TestOtherBaseStruct"},
+		{name: "source directories", args: []string{"diff", "-quiet", "-old", "../..", "-new", "../..", "-filter", "^TestOther", "./testdata"}, code: exitOK},
+	})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// runExamples writes a synthesized JSON example per generated schema into a directory
+func runExamples(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("examples", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	targetFlags := newTargetFlags(flags)
+	dirFlag := flags.String("dir", "", "directory the examples are written to as <schema>.json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: examples -dir examples [generate flags] [packages]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if len(*dirFlag) == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	targets, code := targetFlags.targets(stderr)
+	if code != exitOK {
+		return code
+	}
+
+	registry := make(doc.SpecRegistry)
+	for _, t := range targets {
+		schemas, err := t.schemas()
+		if err != nil {
+			return fail(stderr, exitError, err)
+		}
+		for _, schema := range schemas {
+			registry.AddSchema(doc.ComponentName(schema), schema)
+		}
+	}
+	if len(registry) == 0 {
+		return fail(stderr, exitError, errors.New("no schemas generated"))
+	}
+
+	examples, err := doc.SynthesizeExamples(registry.Values())
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	if err := os.MkdirAll(*dirFlag, 0o755); err != nil {
+		return fail(stderr, exitError, err)
+	}
+	for name, example := range examples {
+		out, err := json.MarshalIndent(example, "", "  ")
+		if err != nil {
+			return fail(stderr, exitError, fmt.Errorf("%s: %w", name, err))
+		}
+		if err := os.WriteFile(filepath.Join(*dirFlag, name+".json"), append(out, '\n'), 0o644); err != nil {
+			return fail(stderr, exitError, err)
+		}
+	}
+
+	return exitOK
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"time"
+)
+
+// runGenerate writes the generated documents, merges them into existing documents or emits overlays
+func runGenerate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	targetFlags := newTargetFlags(flags)
+	watchFlag := flags.Bool("watch", false, "regenerate the outputs when Go files of the packages or their dependencies change")
+	watchIntervalFlag := flags.Duration("watch-interval", 500*time.Millisecond, "interval the Go files are checked for changes in watch mode")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	targets, code := targetFlags.targets(stderr)
+	if code != exitOK {
+		return code
+	}
+	if *watchFlag {
+		return runWatch(targets, *watchIntervalFlag, stderr)
+	}
+
+	for _, t := range targets {
+		if code := generate(t, stdout, stderr); code != exitOK {
+			return code
+		}
+	}
+
+	return exitOK
+}
+
+func generate(t target, stdout, stderr io.Writer) int {
+	specs, err := t.schemas()
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	for _, diagnostic := range doc.ValidateSchemas(specs) {
+		fmt.Fprintln(stderr, diagnostic)
+	}
+
+	if t.merge {
+		return merge(t.output, specs, t.prune, stderr)
+	}
+
+	out, err := t.render(specs, stderr)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	if err = writeOutput(t.output, out, stdout); err != nil {
+		return fail(stderr, exitError, err)
+	}
+
+	return exitOK
+}
+
+// merge merges the schemas into the document of the given file and prints the report
+func merge(path string, specs []spec.Schema, prune bool, stderr io.Writer) int {
+	report, err := doc.MergeSchemasIntoFile(path, specs, prune)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+
+	for _, name := range report.Added {
+		fmt.Fprintf(stderr, "%s: added schema %s\n", path, name)
+	}
+	for _, name := range report.Updated {
+		fmt.Fprintf(stderr, "%s: updated schema %s\n", path, name)
+	}
+	for _, name := range report.Conflicts {
+		fmt.Fprintf(stderr, "%s: skipped schema %s since it is not generated\n", path, name)
+	}
+	for _, name := range report.Stale {
+		if prune {
+			fmt.Fprintf(stderr, "%s: removed stale schema %s\n", path, name)
+		} else {
+			fmt.Fprintf(stderr, "%s: stale schema %s is no longer generated\n", path, name)
+		}
+	}
+
+	return exitOK
+}
@@ -1,35 +1,62 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"github.com/mrahbar/gostruct2openapi/doc"
-	"log"
+	"io"
 	"os"
-	"regexp"
 	"strings"
 )
 
-func main() {
-	packagesFlag := flag.String("packages", "", "comma separated package to scan")
-	filterFlag := flag.String("filter", ".*", "regular expression used to filter struct names")
-	flag.Parse()
+// exit codes of the command
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+	// exitDrift is returned by check if an output file is out of date, by diff on breaking changes
+	// by verify on mismatches and by validate on errors
+	exitDrift = 3
+)
+
+// command runs a sub command with its arguments and returns the exit code
+type command func(args []string, stdout, stderr io.Writer) int
 
-	filter := regexp.MustCompile(*filterFlag)
-	packages := parsePackages(packagesFlag)
+var commands = map[string]command{
+	"generate":   runGenerate,
+	"apply":      runApply,
+	"check":      runCheck,
+	"diff":       runDiff,
+	"verify":     runVerify,
+	"validate":   runValidate,
+	"examples":   runExamples,
+	"serve":      runServe,
+	"serve-mock": runServeMock,
+	"cache":      runCache,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	if len(packages) == 0 {
-		flag.PrintDefaults()
-		os.Exit(1)
+// run dispatches to the sub command named by the first argument, generate is the default sub command
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if cmd, exists := commands[args[0]]; exists {
+			return cmd(args[1:], stdout, stderr)
+		}
 	}
 
-	generator := doc.NewOpenapiGenerator(filter, "json")
-	specs, err := generator.DocumentStruct(packages...)
-	if err != nil {
-		log.Fatal(err)
+	return runGenerate(args, stdout, stderr)
+}
+
+// writeOutput writes to the given file or to stdout if no file is given
+func writeOutput(path string, out []byte, stdout io.Writer) error {
+	if len(path) == 0 {
+		_, err := stdout.Write(out)
+		return err
 	}
 
-	fmt.Println(specs)
+	return doc.WriteFileAtomic(path, out)
 }
 
 func parsePackages(packagesFlag *string) (res []string) {
@@ -41,3 +68,8 @@ func parsePackages(packagesFlag *string) (res []string) {
 
 	return
 }
+
+func fail(stderr io.Writer, code int, err error) int {
+	fmt.Fprintln(stderr, err)
+	return code
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testPackageFlags generate the document of a single struct of the testdata package
+var testPackageFlags = []string{"-quiet", "-title", "Test", "-version", "1.0.0", "-filter", "^TestOtherBaseStruct$", "../../testdata"}
+
+// runCase is a command line with its expected exit code and a part of its expected output
+type runCase struct {
+	name   string
+	args   []string
+	code   int
+	stdout string
+	stderr string
+}
+
+func testRun(t *testing.T, tests []runCase) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tt.code, run(tt.args, &stdout, &stderr), "stdout: %s\nstderr: %s", stdout.String(), stderr.String())
+			assert.True(t, strings.Contains(stdout.String(), tt.stdout), "stdout: %s", stdout.String())
+			assert.True(t, strings.Contains(stderr.String(), tt.stderr), "stderr: %s", stderr.String())
+		})
+	}
+}
+
+func Test_Run_Generate(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "openapi.yaml")
+
+	testRun(t, []runCase{
+		{name: "stdout", args: testPackageFlags, code: exitOK, stdout: `"TestOtherBaseStruct"`},
+		{name: "output", args: append([]string{"generate", "-output", output}, testPackageFlags...), code: exitOK},
+		{name: "format", args: append([]string{"-format", "yaml"}, testPackageFlags...), code: exitOK, stdout: "TestOtherBaseStruct:"},
+		{name: "unknown flag", args: []string{"-unknown"}, code: exitUsage, stderr: "flag provided but not defined"},
+		{name: "invalid filter", args: []string{"-filter", "(", "../../testdata"}, code: exitUsage, stderr: "invalid filter"},
+		{name: "invalid exclude", args: []string{"-exclude", "(", "../../testdata"}, code: exitUsage, stderr: "invalid exclude"},
+		{name: "unknown format", args: []string{"-format", "xml", "../../testdata"}, code: exitUsage},
+		{name: "unknown openapi version", args: []string{"-openapi-version", "2.0", "../../testdata"}, code: exitUsage},
+		{name: "merge without output", args: []string{"-merge", "../../testdata"}, code: exitUsage, stderr: "merge requires an output file"},
+		{name: "unknown package", args: []string{"-quiet", "./unknown"}, code: exitError},
+	})
+
+	content, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "TestOtherBaseStruct:")
+}
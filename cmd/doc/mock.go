@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"github.com/mrahbar/gostruct2openapi/doc/validation"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+)
+
+// runServeMock serves example responses for the operations of the generated document on a local address
+func runServeMock(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve-mock", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	targetFlags := newTargetFlags(flags)
+	addrFlag := flags.String("addr", "localhost:4010", "address the mock server listens on")
+	documentFlag := flags.String("document", "", "hand-written document with the operations the generated schemas are merged into")
+	basePathFlag := flags.String("base-path", "", "prefix of the request paths which is not part of the paths of the document, e.g. /api/v1")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: serve-mock [-addr localhost:4010] [-document openapi.yaml] [generate flags] [packages]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	targets, code := targetFlags.targets(stderr)
+	if code != exitOK {
+		return code
+	}
+	if len(targets) != 1 {
+		return fail(stderr, exitUsage, errors.New("serve-mock serves a single output, select it with -outputs"))
+	}
+
+	content, err := mockDocument(targets[0], *documentFlag)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	handler, err := validation.NewMock(content, validation.WithBasePath(*basePathFlag))
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+
+	listener, err := net.Listen("tcp", *addrFlag)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	logger := targetFlags.logger(stderr)
+	logger.Printf("Serving mock responses on http://%s\n", listener.Addr())
+	if err := http.Serve(listener, logRequests(allowCORS(handler), logger)); err != nil {
+		return fail(stderr, exitError, err)
+	}
+
+	return exitOK
+}
+
+// mockDocument returns the document whose operations are mocked. The generator emits no operations, so the
+// generated schemas are merged into the given hand-written document, respectively the output of a merge target.
+// Without document a GET and POST operation is provided per schema at /<schema>.
+func mockDocument(t target, path string) ([]byte, error) {
+	schemas, err := t.schemas()
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 && t.merge {
+		path = t.output
+	}
+
+	if len(path) > 0 {
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		merged, _, err := doc.MergeSchemas(existing, schemas, false)
+		return merged, err
+	}
+
+	document, err := t.document(schemas)
+	if err != nil {
+		return nil, err
+	}
+	document.Paths = schemaPaths(schemas)
+	return document.Encode(doc.JSONFormat)
+}
+
+// schemaPaths returns a path per schema whose GET operation responds with an example of the schema or, preferring
+// code=404, a problem and whose POST operation validates a request body of the schema
+func schemaPaths(schemas []spec.Schema) map[string]interface{} {
+	notFound := map[string]interface{}{
+		"description": "Not Found",
+		"content": map[string]interface{}{validation.ProblemContentType: map[string]interface{}{
+			"example": validation.Problem{Type: "about:blank", Title: http.StatusText(http.StatusNotFound), Status: http.StatusNotFound},
+		}},
+	}
+	paths := make(map[string]interface{}, len(schemas))
+	for _, schema := range schemas {
+		name := doc.ComponentName(schema)
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+		content := map[string]interface{}{"application/json": map[string]interface{}{"schema": ref}}
+		paths["/"+name] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "get" + name,
+				"responses":   map[string]interface{}{"200": map[string]interface{}{"description": name, "content": content}, "404": notFound},
+			},
+			"post": map[string]interface{}{
+				"operationId": "post" + name,
+				"requestBody": map[string]interface{}{"required": true, "content": content},
+				"responses":   map[string]interface{}{"201": map[string]interface{}{"description": name, "content": content}},
+			},
+		}
+	}
+
+	return paths
+}
+
+// allowCORS answers preflight requests and allows requests of any origin, e.g. of a front-end dev server
+func allowCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if len(origin) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		if r.Method == http.MethodOptions && len(r.Header.Get("Access-Control-Request-Method")) > 0 {
+			w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+			if headers := r.Header.Get("Access-Control-Request-Headers"); len(headers) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// logRequests logs the method, path and response status of each request
+func logRequests(next http.Handler, logger *log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		logger.Printf("%s %s %d\n", r.Method, r.URL.RequestURI(), recorder.status)
+	})
+}
+
+// statusRecorder records the status of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status, s.wroteHeader = status, true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
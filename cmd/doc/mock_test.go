@@ -0,0 +1,125 @@
+package main
+
+import (
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"github.com/mrahbar/gostruct2openapi/doc/validation"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testTarget returns a target of the schemas without packages
+func testTarget(schemas []spec.Schema, err error) target {
+	return target{
+		schemas: func(...doc.Option) ([]spec.Schema, error) {
+			return schemas, err
+		},
+		document: func(schemas []spec.Schema) (*doc.Document, error) {
+			return doc.NewDocument(doc.DefaultOpenAPIVersion, doc.Info{Title: "Test", Version: "1.0.0"}, schemas)
+		},
+	}
+}
+
+func Test_SchemaPaths(t *testing.T) {
+	item := spec.Schema{SchemaProps: spec.SchemaProps{ID: "Item", Type: []string{"object"}}}
+	paths := schemaPaths([]spec.Schema{item})
+
+	assert.Len(t, paths, 1)
+	operations := paths["/Item"].(map[string]interface{})
+	get := operations["get"].(map[string]interface{})
+	assert.Equal(t, "getItem", get["operationId"])
+	assert.Contains(t, get["responses"], "200")
+	assert.Contains(t, get["responses"], "404")
+	post := operations["post"].(map[string]interface{})
+	assert.Equal(t, "postItem", post["operationId"])
+	assert.Equal(t, true, post["requestBody"].(map[string]interface{})["required"])
+}
+
+func Test_ServeMock(t *testing.T) {
+	item := spec.Schema{SchemaProps: spec.SchemaProps{ID: "Item", Type: []string{"object"}, Required: []string{"name"},
+		Properties: map[string]spec.Schema{"name": *spec.StringProperty()}}}
+	content, err := mockDocument(testTarget([]spec.Schema{item}, nil), "")
+	assert.NoError(t, err)
+	mock, err := validation.NewMock(content)
+	assert.NoError(t, err)
+	server := httptest.NewServer(allowCORS(mock))
+	defer server.Close()
+
+	request := func(method, path, body string, header map[string]string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	// requests without origin are passed on without CORS headers
+	resp := request(http.MethodGet, "/Item", "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp = request(http.MethodGet, "/Item", "", map[string]string{"Origin": "http://localhost:3000", "Prefer": "code=404"})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "http://localhost:3000", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", resp.Header.Get("Vary"))
+
+	resp = request(http.MethodPost, "/Item", `{"name": "Widget"}`, map[string]string{"Content-Type": "application/json"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp = request(http.MethodPost, "/Item", `{}`, map[string]string{"Content-Type": "application/json"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// preflight requests are answered without passing them on
+	resp = request(http.MethodOptions, "/Item", "", map[string]string{"Origin": "http://localhost:3000",
+		"Access-Control-Request-Method": "POST", "Access-Control-Request-Headers": "content-type"})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "POST", resp.Header.Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "content-type", resp.Header.Get("Access-Control-Allow-Headers"))
+}
+
+func Test_MockDocument_Merge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`openapi: 3.0.3
+info: {title: Test, version: 1.0.0}
+paths:
+  /items/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: Item
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Item"}
+`), 0o644))
+	item := spec.Schema{SchemaProps: spec.SchemaProps{ID: "Item", Type: []string{"object"},
+		Properties: map[string]spec.Schema{"name": *spec.StringProperty()}}}
+
+	// the generated schemas are merged into the operations of the document
+	content, err := mockDocument(testTarget([]spec.Schema{item}, nil), path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "/items/{id}")
+	assert.NotContains(t, string(content), "getItem")
+	mock, err := validation.NewMock(content)
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	mock.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_Run_ServeMock(t *testing.T) {
+	testRun(t, []runCase{
+		{name: "unknown flag", args: []string{"serve-mock", "-unknown"}, code: exitUsage, stderr: "usage: serve-mock"},
+		{name: "missing document", args: append([]string{"serve-mock", "-document", "missing.yaml"}, testPackageFlags...), code: exitError},
+		{name: "invalid address", args: append([]string{"serve-mock", "-addr", "localhost:-1"}, testPackageFlags...), code: exitError},
+	})
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// viewer is the documentation UI, it is embedded to be served without network access
+//
+//go:embed ui/index.html
+var viewer []byte
+
+// runServe serves the document regenerated from the packages on each request together with the documentation UI
+func runServe(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	targetFlags := newTargetFlags(flags)
+	addrFlag := flags.String("addr", "localhost:8080", "address the documentation is served on")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: serve [-addr localhost:8080] [generate flags] [packages]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	targets, code := targetFlags.targets(stderr)
+	if code != exitOK {
+		return code
+	}
+	if len(targets) != 1 {
+		return fail(stderr, exitUsage, errors.New("serve serves a single output, select it with -outputs"))
+	}
+
+	listener, err := net.Listen("tcp", *addrFlag)
+	if err != nil {
+		return fail(stderr, exitError, err)
+	}
+	logger := targetFlags.logger(stderr)
+	logger.Printf("Serving the documentation on http://%s\n", listener.Addr())
+	if err := http.Serve(listener, logRequests(newDocumentServer(targets[0]), logger)); err != nil {
+		return fail(stderr, exitError, err)
+	}
+
+	return exitOK
+}
+
+// documentServer serves the UI at / and the document at /openapi.json and /openapi.yaml
+type documentServer struct {
+	target target
+	// mu serializes the generation of the document
+	mu  sync.Mutex
+	mux *http.ServeMux
+}
+
+func newDocumentServer(t target) *documentServer {
+	s := &documentServer{target: t, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" && r.URL.Path != "/index.html" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(viewer)
+	})
+	s.mux.HandleFunc("/openapi.json", s.serveDocument(doc.JSONFormat, "application/json"))
+	s.mux.HandleFunc("/openapi.yaml", s.serveDocument(doc.YAMLFormat, "application/yaml"))
+
+	return s
+}
+
+func (s *documentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// serveDocument returns the handler regenerating the document in the format, failures are responded as plain text
+// to be shown by the UI
+func (s *documentServer) serveDocument(format doc.Format, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		out, err := s.render(format)
+		if err != nil {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprintln(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(out)
+	}
+}
+
+// render regenerates the document of the target. The schemas of merge targets are merged into their output file
+// to include the hand-written operations.
+func (s *documentServer) render(format doc.Format) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schemas, err := s.target.schemas()
+	if err != nil {
+		return nil, err
+	}
+	if s.target.merge {
+		existing, err := os.ReadFile(s.target.output)
+		if err != nil {
+			return nil, err
+		}
+		merged, _, err := doc.MergeSchemas(existing, schemas, false)
+		if err != nil {
+			return nil, err
+		}
+		return doc.ConvertDocument(merged, format)
+	}
+
+	document, err := s.target.document(schemas)
+	if err != nil {
+		return nil, err
+	}
+	return document.Encode(format)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_DocumentServer(t *testing.T) {
+	item := spec.Schema{SchemaProps: spec.SchemaProps{ID: "Item", Type: []string{"object"},
+		Properties: map[string]spec.Schema{"name": *spec.StringProperty()}}}
+	server := httptest.NewServer(newDocumentServer(testTarget([]spec.Schema{item}, nil)))
+	defer server.Close()
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{path: "/", status: http.StatusOK, contentType: "text/html; charset=utf-8", body: "<html"},
+		{path: "/index.html", status: http.StatusOK, contentType: "text/html; charset=utf-8", body: "<html"},
+		{path: "/openapi.json", status: http.StatusOK, contentType: "application/json", body: `"Item"`},
+		{path: "/openapi.yaml", status: http.StatusOK, contentType: "application/yaml", body: "Item:"},
+		{path: "/unknown", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+				assert.Contains(t, string(body), tt.body)
+			}
+		})
+	}
+}
+
+func Test_DocumentServer_Failure(t *testing.T) {
+	server := httptest.NewServer(newDocumentServer(testTarget(nil, errors.New("package ./model Load failed"))))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "package ./model Load failed\n", string(body))
+}
+
+func Test_Run_Serve(t *testing.T) {
+	testRun(t, []runCase{
+		{name: "unknown flag", args: []string{"serve", "-unknown"}, code: exitUsage, stderr: "usage: serve"},
+		{name: "invalid address", args: append([]string{"serve", "-addr", "localhost:-1"}, testPackageFlags...), code: exitError},
+	})
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// target is a document to generate, given either by flags or by an output of the config file
+type target struct {
+	// name of the config output, empty for targets given by flags
+	name    string
+	output  string
+	format  doc.Format
+	merge   bool
+	prune   bool
+	overlay bool
+	base    string
+	// dir is the directory relative package patterns are resolved in, empty for the working directory
+	dir string
+	// packages are the package patterns of the target
+	packages []string
+	// schemas generates the schemas, the options are applied after the options of the target
+	schemas func(opts ...doc.Option) ([]spec.Schema, error)
+	// packageSchemas generates the schemas of packages matched by a package pattern of the target with its settings
+	packageSchemas func(pattern string, packages []string, opts ...doc.Option) ([]spec.Schema, error)
+	// scope returns the package patterns generated together with a package pattern of the target by schemas
+	scope func(pattern string) ([]string, error)
+	// document returns the full document of the generated schemas
+	document func(schemas []spec.Schema) (*doc.Document, error)
+}
+
+// targetFlags are the flags selecting the packages and generator settings shared by the sub commands
+type targetFlags struct {
+	flags          *flag.FlagSet
+	packages       *string
+	filter         *string
+	exclude        *string
+	tag            *string
+	output         *string
+	format         *string
+	openapiVersion *string
+	title          *string
+	version        *string
+	merge          *bool
+	prune          *bool
+	overlay        *bool
+	base           *string
+	examples       *bool
+	exampleFuncs   *bool
+	workers        *int
+	cache          *string
+	quiet          *bool
+	config         *string
+	outputs        *string
+}
+
+func newTargetFlags(flags *flag.FlagSet) *targetFlags {
+	return &targetFlags{
+		flags:          flags,
+		packages:       flags.String("packages", "", "comma separated package to scan, further packages can be passed as arguments"),
+		filter:         flags.String("filter", ".*", "regular expression used to filter struct names"),
+		exclude:        flags.String("exclude", "", "regular expression used to exclude struct names"),
+		tag:            flags.String("tag", "json", "struct tag used to name properties"),
+		output:         flags.String("output", "", "file the document is written to, defaults to stdout"),
+		format:         flags.String("format", "", "output format json or yaml, defaults to the extension of the output file or json"),
+		openapiVersion: flags.String("openapi-version", doc.DefaultOpenAPIVersion, "OpenAPI version of the document"),
+		title:          flags.String("title", "", "title of the API"),
+		version:        flags.String("version", "", "version of the API"),
+		merge:          flags.Bool("merge", false, "merge the generated schemas into the existing document of the output file"),
+		prune:          flags.Bool("prune", false, "remove previously generated schemas which are no longer generated when merging"),
+		overlay:        flags.Bool("overlay", false, "write an OpenAPI Overlay adding the generated schemas instead of a document"),
+		base:           flags.String("base", "", "existing document the overlay is computed against, by default all schemas are only updated"),
+		examples:       flags.Bool("examples", false, "set a synthesized example on each schema without @example"),
+		exampleFuncs:   flags.Bool("example-functions", false, "use the JSON output of Example functions in test files as examples"),
+		workers:        flags.Int("workers", 0, "number of packages processed concurrently, defaults to the number of CPUs"),
+		cache:          flags.String("cache", "", "directory of the cache reusing the schemas of unchanged packages, overrides the cache of the config"),
+		quiet:          flags.Bool("quiet", false, "do not print progress messages"),
+		config:         flags.String("config", "", "config file, discovered upwards from the working directory if no packages are given"),
+		outputs:        flags.String("outputs", "", "comma separated names of the config outputs, defaults to all"),
+	}
+}
+
+func (t *targetFlags) logger(stderr io.Writer) *log.Logger {
+	if *t.quiet {
+		return log.New(io.Discard, "", 0)
+	}
+
+	return log.New(stderr, "", 0)
+}
+
+// generatorOptions returns the options given by the flags which apply to flag and config targets
+func (t *targetFlags) generatorOptions(logger *log.Logger) []doc.Option {
+	opts := []doc.Option{doc.WithLogger(logger), doc.WithWorkers(*t.workers)}
+	if len(*t.cache) > 0 {
+		opts = append(opts, doc.WithCache(doc.NewCache(*t.cache)))
+	}
+
+	return opts
+}
+
+// targets returns the targets given by the flags or, if no packages are given, by the config file.
+// The returned exit code is non-zero on invalid flags or config.
+func (t *targetFlags) targets(stderr io.Writer) ([]target, int) {
+	return t.targetsIn("", stderr)
+}
+
+// targetsIn returns the targets like targets but resolves the packages and the config file in the given
+// directory instead of the working directory, e.g. in a git worktree of another version
+func (t *targetFlags) targetsIn(dir string, stderr io.Writer) ([]target, int) {
+	logger := t.logger(stderr)
+
+	packages := append(parsePackages(t.packages), t.flags.Args()...)
+	if len(packages) == 0 || len(*t.config) > 0 {
+		path := *t.config
+		var err error
+		if len(dir) > 0 && len(path) == 0 {
+			path, err = doc.FindConfig(dir)
+		} else if len(dir) > 0 && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		var config *doc.Config
+		if err == nil {
+			config, err = doc.LoadConfig(path)
+		}
+		if errors.Is(err, doc.ErrConfigNotFound) {
+			fmt.Fprintln(stderr, "no packages given and no config file found")
+			t.flags.PrintDefaults()
+			return nil, exitUsage
+		} else if err != nil {
+			return nil, fail(stderr, exitUsage, err)
+		}
+		return t.configTargets(config, logger, stderr)
+	}
+
+	filter, err := regexp.Compile(*t.filter)
+	if err != nil {
+		return nil, fail(stderr, exitUsage, fmt.Errorf("invalid filter: %w", err))
+	}
+	opts := append(t.generatorOptions(logger), doc.WithGoTypeExtension())
+	if len(dir) > 0 {
+		opts = append(opts, doc.WithDir(dir))
+	}
+	if *t.examples {
+		opts = append(opts, doc.WithExamples())
+	}
+	if *t.exampleFuncs {
+		opts = append(opts, doc.WithExampleFunctions())
+	}
+	if len(*t.exclude) > 0 {
+		exclude, err := regexp.Compile(*t.exclude)
+		if err != nil {
+			return nil, fail(stderr, exitUsage, fmt.Errorf("invalid exclude: %w", err))
+		}
+		opts = append(opts, doc.WithExclude(exclude))
+	}
+
+	format := doc.FormatFromPath(*t.output)
+	if len(*t.format) > 0 {
+		if format, err = doc.ParseFormat(*t.format); err != nil {
+			return nil, fail(stderr, exitUsage, err)
+		}
+	}
+
+	if _, err := doc.NewDocument(*t.openapiVersion, doc.Info{}, nil); err != nil {
+		return nil, fail(stderr, exitUsage, err)
+	}
+	if *t.merge && len(*t.output) == 0 {
+		return nil, fail(stderr, exitUsage, errors.New("merge requires an output file"))
+	}
+
+	generate := func(packages []string, extra ...doc.Option) ([]spec.Schema, error) {
+		return doc.NewOpenapiGenerator(filter, *t.tag, append(append([]doc.Option{}, opts...), extra...)...).DocumentStruct(packages...)
+	}
+	return []target{{
+		output:   *t.output,
+		format:   format,
+		merge:    *t.merge,
+		prune:    *t.prune,
+		overlay:  *t.overlay,
+		base:     *t.base,
+		dir:      dir,
+		packages: packages,
+		schemas: func(extra ...doc.Option) ([]spec.Schema, error) {
+			return generate(packages, extra...)
+		},
+		packageSchemas: func(_ string, packages []string, extra ...doc.Option) ([]spec.Schema, error) {
+			return generate(packages, extra...)
+		},
+		scope: func(string) ([]string, error) {
+			return packages, nil
+		},
+		document: func(schemas []spec.Schema) (*doc.Document, error) {
+			return doc.NewDocument(*t.openapiVersion, doc.Info{Title: *t.title, Version: *t.version}, schemas)
+		},
+	}}, exitOK
+}
+
+func (t *targetFlags) configTargets(config *doc.Config, logger *log.Logger, stderr io.Writer) ([]target, int) {
+	outputs, err := config.SelectOutputs(parsePackages(t.outputs)...)
+	if err != nil {
+		return nil, fail(stderr, exitUsage, err)
+	}
+
+	var targets []target
+	for _, output := range outputs {
+		output := output
+		targets = append(targets, target{
+			name:     output.Name,
+			output:   config.OutputPath(output),
+			format:   config.OutputFormat(output),
+			merge:    output.Merge,
+			prune:    output.Prune,
+			overlay:  output.Overlay,
+			base:     config.Path(output.Base),
+			dir:      config.Dir(),
+			packages: output.Packages,
+			schemas: func(opts ...doc.Option) ([]spec.Schema, error) {
+				return config.GenerateSchemas(output, append(t.generatorOptions(logger), opts...)...)
+			},
+			packageSchemas: func(pattern string, packages []string, opts ...doc.Option) ([]spec.Schema, error) {
+				return config.GeneratePatternSchemas(output, pattern, packages, append(t.generatorOptions(logger), opts...)...)
+			},
+			scope: func(pattern string) ([]string, error) {
+				return config.PatternScope(output, pattern)
+			},
+			document: func(schemas []spec.Schema) (*doc.Document, error) {
+				return config.NewDocument(output, schemas)
+			},
+		})
+	}
+
+	return targets, exitOK
+}
+
+// render returns the document or, for overlay targets, the overlay of the schemas encoded in the format of the target.
+// Schemas of the base skipped by the overlay are reported to stderr.
+func (t target) render(schemas []spec.Schema, stderr io.Writer) ([]byte, error) {
+	if t.overlay {
+		var base []byte
+		if len(t.base) > 0 {
+			var err error
+			if base, err = os.ReadFile(t.base); err != nil {
+				return nil, err
+			}
+		}
+		overlay, err := doc.NewOverlay(doc.OverlayInfo{}, schemas, base)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range overlay.Conflicts {
+			fmt.Fprintf(stderr, "%s: skipped schema %s since it is not generated\n", t.base, name)
+		}
+		return overlay.Encode(t.format)
+	}
+
+	document, err := t.document(schemas)
+	if err != nil {
+		return nil, err
+	}
+	return document.Encode(t.format)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"os"
+)
+
+// runValidate validates OpenAPI documents and reports their diagnostics
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: validate openapi.yaml...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	result := exitOK
+	for _, path := range flags.Args() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fail(stderr, exitUsage, err)
+		}
+
+		diagnostics, err := doc.ValidateDocument(content)
+		if err != nil {
+			return fail(stderr, exitError, fmt.Errorf("%s: %w", path, err))
+		}
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintf(stdout, "%s: %s\n", path, diagnostic)
+		}
+		if doc.HasErrors(diagnostics) {
+			result = exitDrift
+		}
+	}
+
+	return result
+}
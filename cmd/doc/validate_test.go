@@ -0,0 +1,21 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Run_Validate(t *testing.T) {
+	dir := testDir(t)
+	generated, invalid := filepath.Join(dir, "generated.yaml"), filepath.Join(dir, "invalid.yaml")
+	assert.NoError(t, os.WriteFile(invalid, []byte("openapi: 2.0.0\ninfo: {title: Test, version: 1.0.0}\npaths: {}\n"), 0o644))
+
+	testRun(t, []runCase{
+		{name: "without documents", args: []string{"validate"}, code: exitUsage, stderr: "usage: validate"},
+		{name: "missing document", args: []string{"validate", filepath.Join(dir, "missing.yaml")}, code: exitUsage},
+		{name: "valid", args: []string{"validate", generated}, code: exitOK},
+		{name: "invalid", args: []string{"validate", generated, invalid}, code: exitDrift, stdout: "unsupported OpenAPI version"},
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"os"
+)
+
+// runVerify checks the components of a hand-written document against the Go types they describe
+func runVerify(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	targetFlags := newTargetFlags(flags)
+	specFlag := flags.String("spec", "", "hand-written OpenAPI document to verify")
+	strictFlag := flags.Bool("strict", false, "fail on components which cannot be mapped to a Go type")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: verify -spec openapi.yaml [generate flags] [packages]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if len(*specFlag) == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	document, err := os.ReadFile(*specFlag)
+	if err != nil {
+		return fail(stderr, exitUsage, err)
+	}
+	targets, code := targetFlags.targets(stderr)
+	if code != exitOK {
+		return code
+	}
+
+	registry := make(doc.SpecRegistry)
+	for _, t := range targets {
+		schemas, err := t.schemas(doc.WithGoTypeExtension())
+		if err != nil {
+			return fail(stderr, exitError, err)
+		}
+		for _, schema := range schemas {
+			registry.AddSchema(doc.ComponentName(schema), schema)
+		}
+	}
+	if len(registry) == 0 {
+		return fail(stderr, exitError, errors.New("no schemas generated"))
+	}
+
+	report, err := doc.VerifyDocument(document, registry.Values())
+	if err != nil {
+		return fail(stderr, exitError, fmt.Errorf("%s: %w", *specFlag, err))
+	}
+
+	for _, mismatch := range report.Mismatches {
+		fmt.Fprintf(stdout, "%s: %s\n", *specFlag, mismatch)
+	}
+	for _, name := range report.Unmapped {
+		fmt.Fprintf(stdout, "%s: %s is not mapped to a Go type, name it after the type or add %s\n", *specFlag, name, doc.GoTypeExtension)
+	}
+	if len(report.Mismatches) > 0 || (*strictFlag && len(report.Unmapped) > 0) {
+		return exitDrift
+	}
+
+	return exitOK
+}
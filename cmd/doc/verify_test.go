@@ -0,0 +1,17 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func Test_Run_Verify(t *testing.T) {
+	dir := testDir(t)
+
+	testRun(t, []runCase{
+		{name: "without spec", args: []string{"verify"}, code: exitUsage, stderr: "usage: verify"},
+		{name: "missing spec", args: append([]string{"verify", "-spec", filepath.Join(dir, "missing.yaml")}, testPackageFlags...), code: exitUsage},
+		{name: "matching", args: append([]string{"verify", "-spec", filepath.Join(dir, "generated.yaml")}, testPackageFlags...), code: exitOK},
+		{name: "mismatch", args: append([]string{"verify", "-spec", filepath.Join(dir, "mismatch.yaml")}, testPackageFlags...), code: exitDrift, stdout: "has type integer but the Go type is string"},
+	})
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// watchUnit is a package of a target which is regenerated on its own when one of its sources changes
+type watchUnit struct {
+	// pattern is the package pattern of the target matching the package, it selects the settings of the package
+	pattern string
+	// pkg is the import path of the package
+	pkg string
+	// dirs are the directories of the package and of its local dependencies
+	dirs    []string
+	schemas []spec.Schema
+	// stale units are new or failed to regenerate, they are regenerated on the next change
+	stale bool
+	// global units depend on all packages of the target, i.e. on implementations of interfaces found in them
+	global bool
+}
+
+// watchedTarget holds the schemas of the units of a target to regenerate only the affected units
+type watchedTarget struct {
+	target
+	units   []*watchUnit
+	schemas []spec.Schema
+}
+
+// runWatch generates the targets and regenerates the packages affected by changes of Go files until interrupted.
+// Every package is generated separately but with the packages generated together with it loaded as scope, and the
+// packages depending on all packages of the target are regenerated on any change, so the output is the same as of
+// generate.
+func runWatch(targets []target, interval time.Duration, stderr io.Writer) int {
+	var watched []*watchedTarget
+	for _, t := range targets {
+		if len(t.output) == 0 {
+			return fail(stderr, exitUsage, errors.New("watch requires an output file"))
+		}
+		watched = append(watched, &watchedTarget{target: t})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, w := range watched {
+		units, err := w.expandUnits()
+		if err != nil {
+			return fail(stderr, exitError, fmt.Errorf("%s: %w", w.output, err))
+		}
+		w.regenerate(units, affectedUnits(units, nil), stderr)
+	}
+	snapshot := takeSnapshot(watchedDirs(watched))
+	fmt.Fprintf(stderr, "watching %d directories for changes\n", len(snapshot))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return exitOK
+		case <-ticker.C:
+		}
+
+		current := takeSnapshot(watchedDirs(watched))
+		if len(snapshot.changedDirs(current)) == 0 {
+			continue
+		}
+		// wait until the files are no longer written, e.g. by a formatter running on save
+		for stable := false; !stable; {
+			select {
+			case <-ctx.Done():
+				return exitOK
+			case <-ticker.C:
+			}
+			next := takeSnapshot(watchedDirs(watched))
+			stable = len(current.changedDirs(next)) == 0
+			current = next
+		}
+
+		changed := snapshot.changedDirs(current)
+		for _, w := range watched {
+			// packages may be added to or removed from the patterns and depend on other packages now
+			units, err := w.expandUnits()
+			if err != nil {
+				fmt.Fprintf(stderr, "%s: %v\n", w.output, err)
+				continue
+			}
+			if len(units) != len(w.units) {
+				// added or removed packages may implement the interfaces of global units
+				markStale(globalUnits(units))
+			}
+			if affected := affectedUnits(units, changed); len(affected) > 0 || len(units) != len(w.units) {
+				w.regenerate(units, affected, stderr)
+			} else {
+				w.units = units
+			}
+		}
+		snapshot = takeSnapshot(watchedDirs(watched))
+	}
+}
+
+// expandUnits returns a unit for each package matched by the package patterns of the target, a package matched by
+// several patterns belongs to the first. The units of packages already watched keep their schemas, new units are stale.
+func (w *watchedTarget) expandUnits() ([]*watchUnit, error) {
+	previous := make(map[string]*watchUnit, len(w.units))
+	for _, unit := range w.units {
+		previous[unit.pkg] = unit
+	}
+
+	var units []*watchUnit
+	expanded := make(map[string]struct{})
+	for _, pattern := range w.packages {
+		dirs, err := doc.PackageDirsByPath(w.dir, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+		pkgs := make([]string, 0, len(dirs))
+		for pkg := range dirs {
+			pkgs = append(pkgs, pkg)
+		}
+		sort.Strings(pkgs)
+
+		for _, pkg := range pkgs {
+			if _, exists := expanded[pkg]; exists {
+				continue
+			}
+			expanded[pkg] = struct{}{}
+			unit := &watchUnit{pattern: pattern, pkg: pkg, dirs: dirs[pkg], stale: true}
+			if p, exists := previous[pkg]; exists {
+				unit.schemas, unit.stale, unit.global = p.schemas, p.stale, p.global
+			}
+			units = append(units, unit)
+		}
+	}
+
+	return units, nil
+}
+
+// affectedUnits returns the stale units and the units depending on a package of the changed directories. If a
+// directory of any unit changed the global units are affected as well.
+func affectedUnits(units []*watchUnit, changed map[string]struct{}) []*watchUnit {
+	dependsOn := func(unit *watchUnit) bool {
+		for _, dir := range unit.dirs {
+			if _, exists := changed[dir]; exists {
+				return true
+			}
+		}
+		return false
+	}
+	changedUnits := false
+	for _, unit := range units {
+		changedUnits = changedUnits || dependsOn(unit)
+	}
+
+	var affected []*watchUnit
+	for _, unit := range units {
+		if unit.stale || (unit.global && changedUnits) || dependsOn(unit) {
+			affected = append(affected, unit)
+		}
+	}
+
+	return affected
+}
+
+// globalUnits returns the units depending on all packages of the target
+func globalUnits(units []*watchUnit) []*watchUnit {
+	var global []*watchUnit
+	for _, unit := range units {
+		if unit.global {
+			global = append(global, unit)
+		}
+	}
+
+	return global
+}
+
+// regenerate generates the schemas of the affected units, writes the output of all units if it changed and prints
+// a summary. The schemas are only taken over if all affected units are generated and the output is written, otherwise
+// the previous schemas and output are kept and the affected units are stale. Each unit is generated with the packages
+// generated together with it by a full run loaded as scope, so that its schemas are the same.
+func (w *watchedTarget) regenerate(units, affected []*watchUnit, stderr io.Writer) {
+	w.units = units
+	start := time.Now()
+	generated := make(map[*watchUnit][]spec.Schema, len(affected))
+	global := make(map[*watchUnit]bool, len(affected))
+	pkgs := make([]string, 0, len(affected))
+	for _, unit := range affected {
+		patterns, err := w.scope(unit.pattern)
+		scope := &doc.Scope{Patterns: patterns}
+		var schemas []spec.Schema
+		if err == nil {
+			schemas, err = w.packageSchemas(unit.pattern, []string{unit.pkg}, doc.WithScope(scope))
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s: %v\n", w.output, unit.pkg, err)
+			markStale(affected)
+			return
+		}
+		generated[unit], global[unit] = schemas, scope.Global
+		pkgs = append(pkgs, unit.pkg)
+	}
+
+	registry := make(doc.SpecRegistry)
+	for _, unit := range units {
+		schemas, exists := generated[unit]
+		if !exists {
+			schemas = unit.schemas
+		}
+		for _, schema := range schemas {
+			registry.AddSchema(doc.ComponentName(schema), schema)
+		}
+	}
+	schemas := registry.Values()
+	report, err := doc.DiffSchemas(w.schemas, schemas)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", w.output, err)
+		markStale(affected)
+		return
+	}
+
+	written, err := w.write(schemas)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", w.output, err)
+		markStale(affected)
+		return
+	}
+	for unit, unitSchemas := range generated {
+		unit.schemas, unit.stale, unit.global = unitSchemas, false, global[unit]
+	}
+	initial := w.schemas == nil
+	w.schemas = schemas
+
+	regenerated := strings.Join(pkgs, ", ")
+	if initial || len(pkgs) == 0 || len(pkgs) > 3 {
+		regenerated = fmt.Sprintf("%d packages", len(pkgs))
+	}
+	summary := fmt.Sprintf("%s: regenerated %s in %s", w.output, regenerated, time.Since(start).Round(time.Millisecond))
+	switch {
+	case initial:
+		summary += fmt.Sprintf(", %d schemas", len(schemas))
+	case len(report.Changes) == 0:
+		summary += ", no schema changed"
+	default:
+		summary += ", " + changeSummary(report)
+	}
+	if !written {
+		summary += ", output is up to date"
+	}
+	fmt.Fprintln(stderr, summary)
+}
+
+// markStale marks the units to be regenerated on the next change
+func markStale(units []*watchUnit) {
+	for _, unit := range units {
+		unit.stale = true
+	}
+}
+
+// write writes the output of the schemas atomically and returns whether its content changed
+func (w *watchedTarget) write(schemas []spec.Schema) (bool, error) {
+	existing, err := os.ReadFile(w.output)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	var out []byte
+	if w.merge {
+		out, _, err = doc.MergeSchemas(existing, schemas, w.prune)
+	} else {
+		out, err = w.render(schemas, io.Discard)
+	}
+	if err != nil || bytes.Equal(out, existing) {
+		return false, err
+	}
+
+	return true, doc.WriteFileAtomic(w.output, out)
+}
+
+// changeSummary lists the added, changed and removed schemas of the report, e.g. "added Item, changed Order (breaking)"
+func changeSummary(report *doc.DiffReport) string {
+	kinds := make(map[string]doc.ChangeKind)
+	breaking := make(map[string]bool)
+	for _, change := range report.Changes {
+		if len(change.Path) == 0 {
+			kinds[change.Schema] = change.Kind
+		} else if _, exists := kinds[change.Schema]; !exists {
+			kinds[change.Schema] = ""
+		}
+		breaking[change.Schema] = breaking[change.Schema] || change.Breaking
+	}
+
+	groups := map[doc.ChangeKind][]string{}
+	for schema, kind := range kinds {
+		name := schema
+		if breaking[schema] && kind == "" {
+			name += " (breaking)"
+		}
+		groups[kind] = append(groups[kind], name)
+	}
+
+	var parts []string
+	for _, group := range []struct {
+		kind  doc.ChangeKind
+		label string
+	}{{doc.Added, "added"}, {"", "changed"}, {doc.Removed, "removed"}} {
+		if names := groups[group.kind]; len(names) > 0 {
+			sort.Strings(names)
+			parts = append(parts, group.label+" "+strings.Join(names, ", "))
+		}
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// watchedDirs returns the directories of all units of the targets
+func watchedDirs(watched []*watchedTarget) []string {
+	var dirs []string
+	for _, w := range watched {
+		for _, unit := range w.units {
+			dirs = append(dirs, unit.dirs...)
+		}
+	}
+
+	return dirs
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot holds the stamps of the Go files by directory and name
+type snapshot map[string]map[string]fileStamp
+
+// takeSnapshot stats the Go files of the directories, new and removed files are detected as well
+func takeSnapshot(dirs []string) snapshot {
+	s := make(snapshot)
+	for _, dir := range dirs {
+		if _, exists := s[dir]; exists {
+			continue
+		}
+		files := make(map[string]fileStamp)
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				files[entry.Name()] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			}
+		}
+		s[dir] = files
+	}
+
+	return s
+}
+
+// changedDirs returns the directories whose Go files differ between the snapshots
+func (s snapshot) changedDirs(other snapshot) map[string]struct{} {
+	changed := make(map[string]struct{})
+	for dir, files := range other {
+		previous, exists := s[dir]
+		if !exists {
+			continue
+		}
+		if len(previous) != len(files) {
+			changed[dir] = struct{}{}
+			continue
+		}
+		for name, stamp := range files {
+			if previousStamp, exists := previous[name]; !exists || previousStamp != stamp {
+				changed[dir] = struct{}{}
+				break
+			}
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_Snapshot_ChangedDirs(t *testing.T) {
+	now := time.Now()
+	previous := snapshot{
+		"model":   {"item.go": {modTime: now, size: 10}},
+		"api":     {"handler.go": {modTime: now, size: 20}},
+		"removed": {"a.go": {modTime: now, size: 1}, "b.go": {modTime: now, size: 1}},
+		"renamed": {"old.go": {modTime: now, size: 1}},
+		"same":    {"same.go": {modTime: now, size: 1}},
+	}
+	current := snapshot{
+		"model":   {"item.go": {modTime: now.Add(time.Second), size: 10}},
+		"api":     {"handler.go": {modTime: now, size: 21}},
+		"removed": {"a.go": {modTime: now, size: 1}},
+		"renamed": {"new.go": {modTime: now, size: 1}},
+		"same":    {"same.go": {modTime: now, size: 1}},
+		// directories which were not watched before are not changed
+		"added": {"c.go": {modTime: now, size: 1}},
+	}
+
+	assert.Equal(t, map[string]struct{}{"model": {}, "api": {}, "removed": {}, "renamed": {}}, previous.changedDirs(current))
+	assert.Empty(t, current.changedDirs(current))
+}
+
+func Test_AffectedUnits(t *testing.T) {
+	model := &watchUnit{pkg: "example.com/model", dirs: []string{"model"}}
+	api := &watchUnit{pkg: "example.com/api", dirs: []string{"api", "model"}}
+	added := &watchUnit{pkg: "example.com/added", dirs: []string{"added"}, stale: true}
+	units := []*watchUnit{model, api, added}
+
+	assert.Equal(t, []*watchUnit{model, api, added}, affectedUnits(units, map[string]struct{}{"model": {}}))
+	assert.Equal(t, []*watchUnit{api, added}, affectedUnits(units, map[string]struct{}{"api": {}}))
+	assert.Equal(t, []*watchUnit{added}, affectedUnits(units, nil))
+	assert.Empty(t, affectedUnits(units[:2], map[string]struct{}{"other": {}}))
+
+	// global units are affected by changes of any unit
+	model.global = true
+	assert.Equal(t, []*watchUnit{model, api, added}, affectedUnits(units, map[string]struct{}{"api": {}}))
+	assert.Empty(t, affectedUnits(units[:2], map[string]struct{}{"other": {}}))
+	assert.Equal(t, []*watchUnit{model}, globalUnits(units))
+}
+
+func Test_Watch_Generate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("go.mod", "module example.com/shapes\n\ngo 1.19\n")
+	write("model/model.go", "package model\n\ntype Shape interface{ Area() float64 }\n\n"+
+		"type Drawing struct {\n\tShape Shape `json:\"shape\"`\n}\n")
+	write("circle/circle.go", "package circle\n\ntype Circle struct {\n\tKind string `json:\"kind\" openapi:\"const=circle\"`\n}\n\n"+
+		"func (Circle) Area() float64 { return 0 }\n")
+
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	targetFlags := newTargetFlags(flags)
+	assert.NoError(t, flags.Parse([]string{"-quiet", "-output", filepath.Join(dir, "openapi.yaml"), "./..."}))
+	targets, code := targetFlags.targetsIn(dir, io.Discard)
+	assert.Equal(t, exitOK, code)
+	w := &watchedTarget{target: targets[0]}
+
+	generated := func() string {
+		schemas, err := w.target.schemas()
+		assert.NoError(t, err)
+		out, err := w.render(schemas, io.Discard)
+		assert.NoError(t, err)
+		return string(out)
+	}
+	watched := func(changed map[string]struct{}) string {
+		units, err := w.expandUnits()
+		assert.NoError(t, err)
+		w.regenerate(units, affectedUnits(units, changed), io.Discard)
+		content, err := os.ReadFile(w.output)
+		assert.NoError(t, err)
+		return string(content)
+	}
+
+	// the implementation of the interface is found in another package like by generate
+	output := watched(nil)
+	assert.Contains(t, output, "#/components/schemas/Circle")
+	assert.Equal(t, generated(), output)
+
+	// a new implementation regenerates the package of the interface, although it does not depend on the changed package
+	write("circle/square.go", "package circle\n\ntype Square struct {\n\tKind string `json:\"kind\" openapi:\"const=square\"`\n}\n\n"+
+		"func (Square) Area() float64 { return 0 }\n")
+	output = watched(map[string]struct{}{filepath.Join(dir, "circle"): {}})
+	assert.Contains(t, output, "#/components/schemas/Square")
+	assert.Equal(t, generated(), output)
+}
+
+func Test_ChangeSummary(t *testing.T) {
+	report := &doc.DiffReport{Changes: []doc.SchemaChange{
+		{Schema: "Item", Kind: doc.Added},
+		{Schema: "Order", Path: "/properties/total", Kind: doc.Removed, Breaking: true},
+		{Schema: "Order", Path: "/properties/note", Kind: doc.Added},
+		{Schema: "Audit", Path: "/description", Kind: doc.Modified},
+		{Schema: "Legacy", Kind: doc.Removed, Breaking: true},
+		{Schema: "Event", Kind: doc.Added},
+	}}
+
+	assert.Equal(t, "added Event, Item; changed Audit, Order (breaking); removed Legacy", changeSummary(report))
+	assert.Empty(t, changeSummary(&doc.DiffReport{}))
+}
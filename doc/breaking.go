@@ -0,0 +1,464 @@
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/util"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ReportFormat is the format of a DiffReport
+type ReportFormat string
+
+const (
+	TextReport     ReportFormat = "text"
+	JSONReport     ReportFormat = "json"
+	MarkdownReport ReportFormat = "markdown"
+)
+
+// ParseReportFormat parses the name of a report format
+func ParseReportFormat(name string) (ReportFormat, error) {
+	switch format := ReportFormat(strings.ToLower(name)); format {
+	case TextReport, JSONReport, MarkdownReport:
+		return format, nil
+	case "md":
+		return MarkdownReport, nil
+	}
+
+	return "", fmt.Errorf("unknown report format %q, expected text, json or markdown", name)
+}
+
+// SchemaChange is a change of a schema between two versions of the models
+type SchemaChange struct {
+	// Schema is the name of the changed schema
+	Schema string `json:"schema"`
+	// Path is the JSON pointer of the changed keyword within the schema, empty if the schema was added or removed
+	Path string     `json:"path"`
+	Kind ChangeKind `json:"kind"`
+	// Breaking changes may break clients relying on the old schema
+	Breaking bool   `json:"breaking"`
+	Message  string `json:"message"`
+}
+
+// DiffReport lists the changes between two versions of the models, ordered by schema and path
+type DiffReport struct {
+	Changes []SchemaChange `json:"changes"`
+}
+
+// HasBreaking returns whether the report contains a breaking change
+func (r *DiffReport) HasBreaking() bool {
+	for _, change := range r.Changes {
+		if change.Breaking {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Write writes the report in the given format
+func (r *DiffReport) Write(w io.Writer, format ReportFormat) error {
+	var breaking, nonBreaking []SchemaChange
+	for _, change := range r.Changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		} else {
+			nonBreaking = append(nonBreaking, change)
+		}
+	}
+
+	var err error
+	write := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	switch format {
+	case TextReport:
+		if len(r.Changes) == 0 {
+			write("no changes\n")
+		}
+		for _, section := range []struct {
+			title   string
+			changes []SchemaChange
+		}{{"Breaking changes", breaking}, {"Non-breaking changes", nonBreaking}} {
+			if len(section.changes) == 0 {
+				continue
+			}
+			write("%s:\n", section.title)
+			for _, change := range section.changes {
+				write("  %s%s: %s\n", change.Schema, change.Path, change.Message)
+			}
+		}
+	case JSONReport:
+		changes := r.Changes
+		if changes == nil {
+			changes = []SchemaChange{}
+		}
+		out, marshalErr := json.MarshalIndent(struct {
+			Breaking    int            `json:"breaking"`
+			NonBreaking int            `json:"nonBreaking"`
+			Changes     []SchemaChange `json:"changes"`
+		}{len(breaking), len(nonBreaking), changes}, "", "  ")
+		if marshalErr != nil {
+			return marshalErr
+		}
+		write("%s\n", out)
+	case MarkdownReport:
+		write("## Schema changes\n\n")
+		write("%d breaking, %d non-breaking\n", len(breaking), len(nonBreaking))
+		if len(r.Changes) > 0 {
+			write("\n| Schema | Path | Change | Breaking |\n|--------|------|--------|----------|\n")
+			for _, change := range append(breaking, nonBreaking...) {
+				mark := "no"
+				if change.Breaking {
+					mark = "**yes**"
+				}
+				write("| %s | %s | %s | %s |\n", markdownCell(change.Schema), markdownCell(change.Path), markdownCell(change.Message), mark)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+
+	return err
+}
+
+func markdownCell(s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// DiffSchemas compares two versions of the generated schemas, matched by their ComponentName, and classifies each change
+// as breaking or non-breaking from the perspective of a client relying on the old schemas. Removed schemas and
+// properties, type, format and reference changes, newly required properties, narrowed enums and tightened
+// constraints are breaking. Added schemas and optional properties, loosened constraints and documentation
+// changes are not.
+func DiffSchemas(old, new []spec.Schema) (*DiffReport, error) {
+	oldSchemas, err := schemaValues(old)
+	if err != nil {
+		return nil, err
+	}
+	newSchemas, err := schemaValues(new)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range oldSchemas {
+		names = append(names, name)
+	}
+	for name := range newSchemas {
+		if _, exists := oldSchemas[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	d := &schemaDiff{report: &DiffReport{}}
+	for _, name := range names {
+		o, inOld := oldSchemas[name]
+		n, inNew := newSchemas[name]
+		d.schema = name
+		switch {
+		case !inOld:
+			d.add("", Added, false, "schema added")
+		case !inNew:
+			d.add("", Removed, true, "schema removed")
+		default:
+			d.compare("", o, n)
+		}
+	}
+
+	return d.report, nil
+}
+
+// schemaValues returns the schemas as written to the components of a document as generic JSON objects
+// keyed by their ComponentName
+func schemaValues(schemas []spec.Schema) (map[string]map[string]interface{}, error) {
+	names, components := componentSchemas(schemas)
+	values := make(map[string]map[string]interface{})
+	for _, name := range names {
+		value, err := schemaValue(components[name])
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// schemaValue returns the schema as generic JSON object
+func schemaValue(schema spec.Schema) (map[string]interface{}, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var value map[string]interface{}
+	err = json.Unmarshal(raw, &value)
+	return value, err
+}
+
+type schemaDiff struct {
+	report *DiffReport
+	schema string
+}
+
+func (d *schemaDiff) add(path string, kind ChangeKind, breaking bool, message string, args ...interface{}) {
+	d.report.Changes = append(d.report.Changes, SchemaChange{
+		Schema:   d.schema,
+		Path:     path,
+		Kind:     kind,
+		Breaking: breaking,
+		Message:  fmt.Sprintf(message, args...),
+	})
+}
+
+// documentationKeywords do not affect the validation of a value
+var documentationKeywords = map[string]struct{}{
+	"id": {}, "title": {}, "description": {}, "example": {}, "examples": {}, "externalDocs": {}, "deprecated": {},
+}
+
+// compare compares the keywords of the old and new schema at the given path
+func (d *schemaDiff) compare(path string, old, new map[string]interface{}) {
+	var keys []string
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range new {
+		if _, exists := old[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		o, inOld := old[key]
+		n, inNew := new[key]
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+		keyPath := path + "/" + jsonschema.EscapePointer(key)
+		kind := Modified
+		if !inOld {
+			kind = Added
+		} else if !inNew {
+			kind = Removed
+		}
+
+		switch key {
+		case "properties":
+			d.compareProperties(keyPath, jsonschema.AsObject(o), jsonschema.AsObject(n), jsonschema.AsStrings(new["required"]))
+		case "items", "not":
+			d.compareSubschema(keyPath, kind, o, n)
+		case "additionalProperties":
+			d.compareAdditionalProperties(keyPath, kind, o, n)
+		case "allOf", "oneOf", "anyOf":
+			d.compareComposition(keyPath, key, jsonschema.AsArray(o), jsonschema.AsArray(n))
+		case "required":
+			d.compareRequired(keyPath, jsonschema.AsStrings(o), jsonschema.AsStrings(n), jsonschema.AsObject(old["properties"]), jsonschema.AsObject(new["properties"]))
+		case "enum":
+			d.compareEnum(keyPath, jsonschema.AsArray(o), jsonschema.AsArray(n))
+		case "maximum", "maxLength", "maxItems", "maxProperties":
+			d.compareLimit(keyPath, key, kind, o, n, func(o, n float64) bool { return n < o })
+		case "minimum", "minLength", "minItems", "minProperties":
+			d.compareLimit(keyPath, key, kind, o, n, func(o, n float64) bool { return n > o })
+		case "exclusiveMaximum", "exclusiveMinimum", "uniqueItems":
+			d.add(keyPath, kind, n == true, "%s changed from %v to %v", key, valueOrNone(o), valueOrNone(n))
+		case "nullable":
+			d.add(keyPath, kind, n != true, "%s changed from %v to %v", key, valueOrNone(o), valueOrNone(n))
+		case "format", "pattern":
+			d.add(keyPath, kind, inNew, "%s changed from %v to %v", key, valueOrNone(o), valueOrNone(n))
+		case "discriminator":
+			d.compareDiscriminator(keyPath, jsonschema.AsObject(o), jsonschema.AsObject(n))
+		default:
+			_, documentation := documentationKeywords[key]
+			breaking := !documentation && !strings.HasPrefix(key, "x-")
+			d.add(keyPath, kind, breaking, "%s changed from %v to %v", key, valueOrNone(o), valueOrNone(n))
+		}
+	}
+}
+
+func (d *schemaDiff) compareProperties(path string, old, new map[string]interface{}, required []string) {
+	var names []string
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range new {
+		if _, exists := old[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		o, inOld := old[name]
+		n, inNew := new[name]
+		propertyPath := path + "/" + jsonschema.EscapePointer(name)
+		switch {
+		case !inOld && util.Contains(required, name):
+			d.add(propertyPath, Added, true, "required property %s added", name)
+		case !inOld:
+			d.add(propertyPath, Added, false, "optional property %s added", name)
+		case !inNew:
+			d.add(propertyPath, Removed, true, "property %s removed", name)
+		default:
+			d.compare(propertyPath, jsonschema.AsObject(o), jsonschema.AsObject(n))
+		}
+	}
+}
+
+func (d *schemaDiff) compareSubschema(path string, kind ChangeKind, old, new interface{}) {
+	if kind == Modified {
+		d.compare(path, jsonschema.AsObject(old), jsonschema.AsObject(new))
+		return
+	}
+	d.add(path, kind, kind == Added, "%s %s", lastToken(path), kind)
+}
+
+// compareAdditionalProperties compares the additionalProperties keyword which is either a boolean or a schema
+func (d *schemaDiff) compareAdditionalProperties(path string, kind ChangeKind, old, new interface{}) {
+	oldSchema, oldIsSchema := old.(map[string]interface{})
+	newSchema, newIsSchema := new.(map[string]interface{})
+	switch {
+	case oldIsSchema && newIsSchema:
+		d.compare(path, oldSchema, newSchema)
+	case new == false:
+		d.add(path, kind, true, "additional properties are no longer allowed")
+	case new == nil || new == true:
+		d.add(path, kind, false, "any additional properties are allowed")
+	default:
+		d.add(path, kind, true, "additional properties are restricted to a schema")
+	}
+}
+
+// compareComposition compares the subschemas of allOf, oneOf and anyOf by index. Additional allOf subschemas
+// add constraints while additional oneOf and anyOf subschemas add alternatives.
+func (d *schemaDiff) compareComposition(path, key string, old, new []interface{}) {
+	for i := 0; i < len(old) || i < len(new); i++ {
+		indexPath := fmt.Sprintf("%s/%d", path, i)
+		switch {
+		case i >= len(old):
+			d.add(indexPath, Added, key == "allOf", "%s subschema added", key)
+		case i >= len(new):
+			d.add(indexPath, Removed, key != "allOf", "%s subschema removed", key)
+		default:
+			d.compare(indexPath, jsonschema.AsObject(old[i]), jsonschema.AsObject(new[i]))
+		}
+	}
+}
+
+// compareRequired compares the required properties. Properties which are added or removed are reported by
+// compareProperties, including whether an added property is required.
+func (d *schemaDiff) compareRequired(path string, old, new []string, oldProperties, newProperties map[string]interface{}) {
+	for _, name := range new {
+		if _, existed := oldProperties[name]; !existed && newProperties[name] != nil {
+			continue
+		}
+		if !util.Contains(old, name) {
+			d.add(path, Added, true, "property %s is required", name)
+		}
+	}
+	for _, name := range old {
+		if _, exists := newProperties[name]; !exists && oldProperties[name] != nil {
+			continue
+		}
+		if !util.Contains(new, name) {
+			d.add(path, Removed, false, "property %s is no longer required", name)
+		}
+	}
+}
+
+func (d *schemaDiff) compareEnum(path string, old, new []interface{}) {
+	if len(old) == 0 {
+		d.add(path, Added, true, "values are restricted to %s", jsonschema.JSONString(new))
+		return
+	}
+	if len(new) == 0 {
+		d.add(path, Removed, false, "values are no longer restricted")
+		return
+	}
+
+	for _, value := range old {
+		if !containsValue(new, value) {
+			d.add(path, Removed, true, "enum value %s removed", jsonschema.JSONString(value))
+		}
+	}
+	for _, value := range new {
+		if !containsValue(old, value) {
+			d.add(path, Added, false, "enum value %s added", jsonschema.JSONString(value))
+		}
+	}
+}
+
+// compareLimit compares a numeric constraint, tightened returns whether the new limit is stricter than the old
+func (d *schemaDiff) compareLimit(path, key string, kind ChangeKind, old, new interface{}, tightened func(o, n float64) bool) {
+	oldLimit, _ := old.(float64)
+	newLimit, _ := new.(float64)
+	breaking := kind == Added || (kind == Modified && tightened(oldLimit, newLimit))
+	d.add(path, kind, breaking, "%s changed from %v to %v", key, valueOrNone(old), valueOrNone(new))
+}
+
+func (d *schemaDiff) compareDiscriminator(path string, old, new map[string]interface{}) {
+	if old == nil || new == nil || old["propertyName"] != new["propertyName"] {
+		d.add(path, Modified, true, "discriminator changed from %s to %s", jsonschema.JSONString(old), jsonschema.JSONString(new))
+		return
+	}
+
+	oldMapping, newMapping := jsonschema.AsObject(old["mapping"]), jsonschema.AsObject(new["mapping"])
+	var values []string
+	for value := range oldMapping {
+		values = append(values, value)
+	}
+	for value := range newMapping {
+		if _, exists := oldMapping[value]; !exists {
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		o, inOld := oldMapping[value]
+		n, inNew := newMapping[value]
+		mappingPath := path + "/mapping/" + jsonschema.EscapePointer(value)
+		switch {
+		case !inOld:
+			d.add(mappingPath, Added, false, "discriminator value %s added", value)
+		case !inNew:
+			d.add(mappingPath, Removed, true, "discriminator value %s removed", value)
+		case o != n:
+			d.add(mappingPath, Modified, true, "discriminator value %s maps to %v instead of %v", value, n, o)
+		}
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func valueOrNone(v interface{}) string {
+	if v == nil {
+		return "none"
+	}
+
+	return jsonschema.JSONString(v)
+}
+
+func lastToken(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,121 @@
+package doc
+
+import (
+	"bytes"
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	oldDiffSchemas = []spec.Schema{
+		{SchemaProps: spec.SchemaProps{
+			ID:       "Item",
+			Type:     []string{"object"},
+			Required: []string{"id", "note"},
+			Properties: map[string]spec.Schema{
+				"id":     *spec.StringProperty(),
+				"name":   *spec.StringProperty().WithMaxLength(10).WithDescription("Name"),
+				"status": *spec.StringProperty().WithEnum("new", "done"),
+				"count":  *spec.Int64Property(),
+				"note":   *spec.StringProperty(),
+			},
+		}},
+		{SchemaProps: spec.SchemaProps{ID: "Removed", Type: []string{"object"}}},
+	}
+	newDiffSchemas = []spec.Schema{
+		{SchemaProps: spec.SchemaProps{
+			ID:       "Item",
+			Type:     []string{"object"},
+			Required: []string{"id", "name", "owner"},
+			Properties: map[string]spec.Schema{
+				"id":     *spec.StringProperty(),
+				"name":   *spec.StringProperty().WithMaxLength(20).WithDescription("Name of the item"),
+				"status": *spec.StringProperty().WithEnum("new", "archived"),
+				"count":  *spec.StringProperty(),
+				"owner":  *spec.StringProperty(),
+				"tag":    *spec.StringProperty().WithPattern("^[a-z]+$"),
+			},
+		}},
+		{SchemaProps: spec.SchemaProps{ID: "Added", Type: []string{"object"}}},
+	}
+)
+
+func Test_DiffSchemas(t *testing.T) {
+	report, err := DiffSchemas(oldDiffSchemas, newDiffSchemas)
+	assert.NoError(t, err)
+	assert.True(t, report.HasBreaking())
+	// the required-ness of the added owner and the removed note is reported with the property only
+	assert.Equal(t, []SchemaChange{
+		{Schema: "Added", Kind: Added, Message: "schema added"},
+		{Schema: "Item", Path: "/properties/count/format", Kind: Removed, Message: "format changed from \"int64\" to none"},
+		{Schema: "Item", Path: "/properties/count/type", Kind: Modified, Breaking: true, Message: "type changed from \"integer\" to \"string\""},
+		{Schema: "Item", Path: "/properties/name/description", Kind: Modified, Message: "description changed from \"Name\" to \"Name of the item\""},
+		{Schema: "Item", Path: "/properties/name/maxLength", Kind: Modified, Message: "maxLength changed from 10 to 20"},
+		{Schema: "Item", Path: "/properties/note", Kind: Removed, Breaking: true, Message: "property note removed"},
+		{Schema: "Item", Path: "/properties/owner", Kind: Added, Breaking: true, Message: "required property owner added"},
+		{Schema: "Item", Path: "/properties/status/enum", Kind: Removed, Breaking: true, Message: "enum value \"done\" removed"},
+		{Schema: "Item", Path: "/properties/status/enum", Kind: Added, Message: "enum value \"archived\" added"},
+		{Schema: "Item", Path: "/properties/tag", Kind: Added, Message: "optional property tag added"},
+		{Schema: "Item", Path: "/required", Kind: Added, Breaking: true, Message: "property name is required"},
+		{Schema: "Removed", Kind: Removed, Breaking: true, Message: "schema removed"},
+	}, report.Changes)
+
+	report, err = DiffSchemas(newDiffSchemas, newDiffSchemas)
+	assert.NoError(t, err)
+	assert.False(t, report.HasBreaking())
+	assert.Empty(t, report.Changes)
+}
+
+func Test_DiffSchemas_Constraints(t *testing.T) {
+	oldList := spec.ArrayProperty(spec.Int64Property().WithMinimum(0, false).WithMaximum(100, false))
+	oldList.ID = "List"
+	tightenedList := spec.ArrayProperty(spec.Int64Property().WithMinimum(1, false).WithMaximum(100, false)).WithMaxItems(5)
+	tightenedList.ID = "List"
+	old, tightened := []spec.Schema{*oldList}, []spec.Schema{*tightenedList}
+
+	report, err := DiffSchemas(old, tightened)
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaChange{
+		{Schema: "List", Path: "/items/minimum", Kind: Modified, Breaking: true, Message: "minimum changed from 0 to 1"},
+		{Schema: "List", Path: "/maxItems", Kind: Added, Breaking: true, Message: "maxItems changed from none to 5"},
+	}, report.Changes)
+
+	report, err = DiffSchemas(tightened, old)
+	assert.NoError(t, err)
+	assert.False(t, report.HasBreaking())
+	assert.Len(t, report.Changes, 2)
+}
+
+func Test_DiffReport_Write(t *testing.T) {
+	report := &DiffReport{Changes: []SchemaChange{
+		{Schema: "Item", Path: "/properties/note", Kind: Removed, Breaking: true, Message: "property note removed"},
+		{Schema: "Item", Path: "/properties/tag", Kind: Added, Message: "optional property tag added"},
+	}}
+
+	var out bytes.Buffer
+	assert.NoError(t, report.Write(&out, TextReport))
+	assert.Equal(t, `Breaking changes:
+  Item/properties/note: property note removed
+Non-breaking changes:
+  Item/properties/tag: optional property tag added
+`, out.String())
+
+	out.Reset()
+	assert.NoError(t, report.Write(&out, MarkdownReport))
+	assert.Equal(t, "## Schema changes\n\n1 breaking, 1 non-breaking\n\n"+
+		"| Schema | Path | Change | Breaking |\n|--------|------|--------|----------|\n"+
+		"| `Item` | `/properties/note` | `property note removed` | **yes** |\n"+
+		"| `Item` | `/properties/tag` | `optional property tag added` | no |\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, report.Write(&out, JSONReport))
+	assert.JSONEq(t, `{
+		"breaking": 1,
+		"nonBreaking": 1,
+		"changes": [
+			{"schema": "Item", "path": "/properties/note", "kind": "removed", "breaking": true, "message": "property note removed"},
+			{"schema": "Item", "path": "/properties/tag", "kind": "added", "breaking": false, "message": "optional property tag added"}
+		]
+	}`, out.String())
+}
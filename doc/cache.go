@@ -0,0 +1,405 @@
+package doc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/tools/go/packages"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheFormat is incremented whenever the cached results or the traversal change incompatibly
+const cacheFormat = 2
+
+const modulePath = "github.com/mrahbar/gostruct2openapi"
+
+// Cache stores the schemas generated per package on disk to reuse them for unchanged packages, e.g. across CI runs.
+// Entries are keyed by the contents of the files of the package and of its dependencies within local modules, the
+// versions of the other modules it depends on, the Go version, the generator version and the generator settings.
+type Cache struct {
+	dir string
+}
+
+// NewCache returns the cache stored in dir, which is created on the first write
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Dir returns the directory of the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// CacheEntry describes the cached schemas of a package
+type CacheEntry struct {
+	Key     string `json:"key"`
+	Package string `json:"package"`
+	// Schemas is the number of schemas generated by the traversal of the package, including referenced structs
+	Schemas int   `json:"schemas"`
+	Size    int64 `json:"size"`
+	// Used is the time the entry was last written or read
+	Used time.Time `json:"used"`
+}
+
+// cachedPackage is the content of a cache entry holding a packageResult
+type cachedPackage struct {
+	Format  int    `json:"format"`
+	Package string `json:"package"`
+	// Roots is the key of all generated packages if the result depends on them, see packageResult.Global
+	Roots string `json:"roots,omitempty"`
+	// Schemas are encoded separately since spec.Schema cannot decode the discriminator objects of OpenAPI 3
+	Schemas map[string]json.RawMessage `json:"schemas"`
+	Targets []string                   `json:"targets"`
+	Methods []string                   `json:"methods"`
+	Global  bool                       `json:"global,omitempty"`
+}
+
+// Entries returns the entries of the cache sorted by package and last use
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == c.dir {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		key := strings.TrimSuffix(d.Name(), ".json")
+		if !isCacheEntry(c.dir, path, key) {
+			// other files in the directory are no entries
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry := CacheEntry{Key: key, Size: info.Size(), Used: info.ModTime()}
+		if cached, err := readCachedPackage(path); err == nil {
+			entry.Package = cached.Package
+			entry.Schemas = len(cached.Schemas)
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Package != entries[j].Package {
+			return entries[i].Package < entries[j].Package
+		}
+		return entries[i].Used.After(entries[j].Used)
+	})
+	return entries, nil
+}
+
+// Prune removes the entries which were not used since the given time and returns them. A zero time removes all entries.
+func (c *Cache) Prune(unusedSince time.Time) ([]CacheEntry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []CacheEntry
+	for _, entry := range entries {
+		if !unusedSince.IsZero() && !entry.Used.Before(unusedSince) {
+			continue
+		}
+		if err := os.Remove(c.path(entry.Key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return pruned, err
+		}
+		// the directory of the key prefix is only removed if empty
+		_ = os.Remove(filepath.Dir(c.path(entry.Key)))
+		pruned = append(pruned, entry)
+	}
+
+	return pruned, nil
+}
+
+// cacheKeyPattern matches the keys of entries, the hex encoded SHA-256 of the package
+var cacheKeyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// isCacheEntry reports whether the file is laid out like the entry of the key, i.e. <2 hex>/<64 hex>.json
+func isCacheEntry(dir, path, key string) bool {
+	if !cacheKeyPattern.MatchString(key) {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel == filepath.Join(key[:2], key+".json")
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// load returns the cached result of the key or nil. Results depending on all generated packages are only returned
+// if these are unchanged, i.e. have the given roots key.
+func (c *Cache) load(key, roots string) *packageResult {
+	path := c.path(key)
+	cached, err := readCachedPackage(path)
+	if err != nil || cached.Format != cacheFormat || (cached.Global && cached.Roots != roots) {
+		return nil
+	}
+
+	result := &packageResult{Schemas: make(SpecRegistry, len(cached.Schemas)), Targets: cached.Targets, Methods: cached.Methods, Global: cached.Global}
+	for key, raw := range cached.Schemas {
+		if result.Schemas[key], err = unmarshalSchema(raw); err != nil {
+			return nil
+		}
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return result
+}
+
+// store writes the result of the package atomically, see WriteFileAtomic
+func (c *Cache) store(key, roots, pkg string, result *packageResult) error {
+	cached := cachedPackage{Format: cacheFormat, Package: pkg, Schemas: make(map[string]json.RawMessage, len(result.Schemas)),
+		Targets: result.Targets, Methods: result.Methods, Global: result.Global}
+	if result.Global {
+		cached.Roots = roots
+	}
+	for key, schema := range result.Schemas {
+		raw, err := json.Marshal(schema)
+		if err != nil {
+			return err
+		}
+		cached.Schemas[key] = raw
+	}
+	out, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, out)
+}
+
+func readCachedPackage(path string) (*cachedPackage, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := &cachedPackage{}
+	if err := json.Unmarshal(content, cached); err != nil {
+		return nil, err
+	}
+	return cached, nil
+}
+
+// cacheKeys returns the cache key of each root package by path and the key of all roots. The key of a package covers
+// the generator and its settings as well as the package and its dependencies: the files of packages of local modules,
+// the versions of other modules and, by the Go version, the standard library.
+func (o *openapiGenerator) cacheKeys(roots []*packages.Package) (map[string]string, string, error) {
+	settings, err := o.cacheSettings()
+	if err != nil {
+		return nil, "", err
+	}
+
+	rootPaths := make(map[string]struct{}, len(roots))
+	for _, root := range roots {
+		rootPaths[root.PkgPath] = struct{}{}
+	}
+	hashes := make(map[string]string)
+	keys := make(map[string]string, len(roots))
+	all := sha256.New()
+	for _, root := range roots {
+		var closure []*packages.Package
+		packages.Visit([]*packages.Package{root}, nil, func(pkg *packages.Package) {
+			closure = append(closure, pkg)
+		})
+		sort.Slice(closure, func(i, j int) bool {
+			return closure[i].PkgPath < closure[j].PkgPath
+		})
+
+		h := sha256.New()
+		fmt.Fprintf(h, "%s\npackage %s\n", settings, root.PkgPath)
+		for _, pkg := range closure {
+			_, isRoot := rootPaths[pkg.PkgPath]
+			fmt.Fprintf(h, "%s root=%t\n", pkg.PkgPath, isRoot)
+			if pkg.Module == nil {
+				// the standard library is covered by the Go version
+				continue
+			}
+			if !isLocalModule(pkg.Module) {
+				module := pkg.Module
+				if module.Replace != nil {
+					module = module.Replace
+				}
+				fmt.Fprintf(h, "module %s@%s\n", module.Path, module.Version)
+				continue
+			}
+			files := append([]string{}, pkg.GoFiles...)
+			if isRoot && o.exampleFunctions && len(pkg.GoFiles) > 0 {
+				tests, _ := filepath.Glob(filepath.Join(filepath.Dir(pkg.GoFiles[0]), "*_test.go"))
+				files = append(files, tests...)
+			}
+			for _, file := range files {
+				hash, err := fileHash(file, hashes)
+				if err != nil {
+					return nil, "", err
+				}
+				fmt.Fprintf(h, "%s %s\n", filepath.Base(file), hash)
+			}
+		}
+
+		keys[root.PkgPath] = hex.EncodeToString(h.Sum(nil))
+		fmt.Fprintf(all, "%s %s\n", root.PkgPath, keys[root.PkgPath])
+	}
+
+	return keys, hex.EncodeToString(all.Sum(nil)), nil
+}
+
+// cacheSettings returns the versions and settings the generated schemas depend on
+func (o *openapiGenerator) cacheSettings() ([]byte, error) {
+	version, err := generatorVersion()
+	if err != nil {
+		return nil, err
+	}
+	exclude := ""
+	if o.exclude != nil {
+		exclude = o.exclude.String()
+	}
+
+	return json.Marshal(struct {
+		Format           int                    `json:"format"`
+		Generator        string                 `json:"generator"`
+		Go               string                 `json:"go"`
+		Tag              string                 `json:"tag"`
+		Filter           string                 `json:"filter"`
+		Exclude          string                 `json:"exclude"`
+		Embedded         EmbeddedStructMode     `json:"embedded"`
+		Variants         []SchemaVariant        `json:"variants"`
+		Types            map[string]TypeMapping `json:"types"`
+		GoType           bool                   `json:"goType"`
+		ExampleFunctions bool                   `json:"exampleFunctions"`
+	}{cacheFormat, version, runtime.Version(), o.structTag, o.filter.String(), exclude, o.embeddedStructMode,
+		o.schemaVariants, o.typeRegistry.mappings, o.goTypeExtension, o.exampleFunctions})
+}
+
+// generatorVersion returns the version of the generator module. Development builds are identified by their VCS
+// revision if unmodified and otherwise, like local replacements, by the hash of the sources of the module.
+func generatorVersion() (string, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", errors.New("generator version is unknown: no build info")
+	}
+
+	if info.Main.Path == modulePath {
+		settings := make(map[string]string)
+		for _, setting := range info.Settings {
+			settings[setting.Key] = setting.Value
+		}
+		if revision := settings["vcs.revision"]; len(revision) > 0 && settings["vcs.modified"] == "false" {
+			return info.Main.Version + " " + revision, nil
+		}
+		if isReleaseVersion(info.Main.Version) {
+			return info.Main.Version, nil
+		}
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace == nil && isReleaseVersion(dep.Version) {
+			return dep.Version, nil
+		}
+		if dep.Replace != nil && isReleaseVersion(dep.Replace.Version) {
+			return dep.Replace.Path + "@" + dep.Replace.Version, nil
+		}
+	}
+
+	return generatorSourceHash()
+}
+
+// isReleaseVersion reports whether the module version identifies its content, unlike (devel), the empty version of
+// a directory replacement or a version with uncommitted changes
+func isReleaseVersion(version string) bool {
+	return len(version) > 0 && version != "(devel)" && !strings.HasSuffix(version, "+dirty")
+}
+
+var sourceHash struct {
+	once sync.Once
+	hash string
+	err  error
+}
+
+// generatorSourceHash returns the hash of the Go files and the go.mod of the generator module, which are located by
+// the path this file was compiled from. Builds with -trimpath or without the sources on disk have no hash.
+func generatorSourceHash() (string, error) {
+	sourceHash.once.Do(func() {
+		_, file, _, ok := runtime.Caller(0)
+		root := filepath.Dir(filepath.Dir(file))
+		if content, err := os.ReadFile(filepath.Join(root, "go.mod")); !ok || err != nil ||
+			!strings.HasPrefix(string(content), "module "+modulePath+"\n") {
+			sourceHash.err = fmt.Errorf("generator version is unknown: sources of %s not found", modulePath)
+			return
+		}
+
+		h := sha256.New()
+		hashes := make(map[string]string)
+		sourceHash.err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			name := d.Name()
+			if d.IsDir() {
+				if path != root && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if name != "go.mod" && (filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go")) {
+				return nil
+			}
+			hash, err := fileHash(path, hashes)
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(root, path)
+			fmt.Fprintf(h, "%s %s\n", filepath.ToSlash(rel), hash)
+			return nil
+		})
+		sourceHash.hash = "source " + hex.EncodeToString(h.Sum(nil))
+	})
+
+	return sourceHash.hash, sourceHash.err
+}
+
+// fileHash returns the SHA-256 of the file content, hashes are memoized by path
+func fileHash(path string, hashes map[string]string) (string, error) {
+	if hash, exists := hashes[path]; exists {
+		return hash, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	hashes[path] = hex.EncodeToString(h.Sum(nil))
+	return hashes[path], nil
+}
@@ -0,0 +1,103 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_Cache(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	generate := func() ([]byte, string) {
+		var logs bytes.Buffer
+		generator := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", WithCache(cache), WithLogger(log.New(&logs, "", 0)))
+		specs, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata", "github.com/mrahbar/gostruct2openapi/testdata")
+		assert.NoError(t, err)
+		out, err := json.Marshal(specs)
+		assert.NoError(t, err)
+		return out, logs.String()
+	}
+
+	cold, logs := generate()
+	assert.NotContains(t, logs, "Using cached schemas")
+	entries, err := cache.Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "github.com/mrahbar/gostruct2openapi/doc/testdata", entries[0].Package)
+	assert.NotZero(t, entries[0].Schemas)
+
+	warm, logs := generate()
+	assert.Contains(t, logs, "Using cached schemas of 2 packages")
+	assert.NotContains(t, logs, "Processing struct")
+	assert.Equal(t, string(cold), string(warm))
+
+	// files which are not laid out like entries are ignored
+	strays := []string{"a.json", filepath.Join("ab", "short.json"), filepath.Join("zz", entries[0].Key+".json")}
+	for _, stray := range strays {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(cache.Dir(), stray)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(cache.Dir(), stray), []byte("{}"), 0o644))
+	}
+	entries, err = cache.Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	pruned, err := cache.Prune(time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, pruned)
+	pruned, err = cache.Prune(time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, pruned, 2)
+	entries, err = cache.Entries()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	for _, stray := range strays {
+		assert.FileExists(t, filepath.Join(cache.Dir(), stray))
+	}
+}
+
+func Test_Cache_Invalidation(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("go.mod", "module example.com/cached\n\ngo 1.19\n")
+	write("model.go", "package cached\n\ntype Item struct {\n\tName string `json:\"name\"`\n}\n")
+
+	cache := NewCache(t.TempDir())
+	generate := func(tag string) spec.SchemaProperties {
+		generator := NewOpenapiGenerator(regexp.MustCompile(".*"), tag, WithDir(dir), WithCache(cache), WithLogger(log.New(io.Discard, "", 0)))
+		specs, err := generator.DocumentStruct("./...")
+		assert.NoError(t, err)
+		assert.Len(t, specs, 1)
+		return specs[0].Properties
+	}
+
+	assert.Contains(t, generate("json"), "name")
+	// changed files and settings result in new entries
+	write("model.go", "package cached\n\ntype Item struct {\n\tTitle string `json:\"title\"`\n}\n")
+	assert.Contains(t, generate("json"), "title")
+	assert.Contains(t, generate("yaml"), "Title")
+	entries, err := cache.Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func Test_GeneratorVersion(t *testing.T) {
+	assert.True(t, isReleaseVersion("v1.2.0"))
+	assert.False(t, isReleaseVersion(""))
+	assert.False(t, isReleaseVersion("(devel)"))
+	assert.False(t, isReleaseVersion("v1.2.1-0.20240102150405-abcdef123456+dirty"))
+
+	// the test binary is a development build, it is identified by the hash of the sources
+	version, err := generatorVersion()
+	assert.NoError(t, err)
+	assert.Regexp(t, "^source [0-9a-f]{64}$", version)
+}
@@ -0,0 +1,136 @@
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ChangeKind is the kind of a difference between two documents
+type ChangeKind string
+
+const (
+	// Added values exist only in the new document
+	Added ChangeKind = "added"
+	// Removed values exist only in the old document
+	Removed ChangeKind = "removed"
+	// Modified values exist in both documents with different values
+	Modified ChangeKind = "modified"
+)
+
+// Change is a difference between two documents at the JSON pointer Path
+type Change struct {
+	Path string      `json:"path"`
+	Kind ChangeKind  `json:"kind"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// String returns the change as a line of a diff, values of objects and arrays are omitted
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return "+ " + c.Path + valueSuffix(": ", c.New)
+	case Removed:
+		return "- " + c.Path + valueSuffix(": ", c.Old)
+	default:
+		return "~ " + c.Path + valueSuffix(": ", c.Old) + valueSuffix(" -> ", c.New)
+	}
+}
+
+func valueSuffix(prefix string, v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return ""
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return prefix + string(raw)
+}
+
+// CompareDocuments compares the YAML or JSON documents semantically, i.e. ignoring key order and formatting,
+// and returns the changes turning the old into the new document ordered by path
+func CompareDocuments(old, new []byte) ([]Change, error) {
+	oldNode, err := parseNode(old)
+	if err != nil {
+		return nil, fmt.Errorf("old document: %w", err)
+	}
+	newNode, err := parseNode(new)
+	if err != nil {
+		return nil, fmt.Errorf("new document: %w", err)
+	}
+
+	oldValue, err := fromNode(oldNode)
+	if err != nil {
+		return nil, err
+	}
+	newValue, err := fromNode(newNode)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareValues("", oldValue, newValue), nil
+}
+
+// compareValues returns the changes between the generic JSON values. Objects are compared by key and arrays
+// by index, any other difference modifies the value as a whole.
+func compareValues(path string, old, new interface{}) []Change {
+	switch oldValue := old.(type) {
+	case map[string]interface{}:
+		if newValue, ok := new.(map[string]interface{}); ok {
+			var keys []string
+			for key := range oldValue {
+				keys = append(keys, key)
+			}
+			for key := range newValue {
+				if _, exists := oldValue[key]; !exists {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+
+			var changes []Change
+			for _, key := range keys {
+				keyPath := path + "/" + jsonschema.EscapePointer(key)
+				o, inOld := oldValue[key]
+				n, inNew := newValue[key]
+				switch {
+				case !inOld:
+					changes = append(changes, Change{Path: keyPath, Kind: Added, New: n})
+				case !inNew:
+					changes = append(changes, Change{Path: keyPath, Kind: Removed, Old: o})
+				default:
+					changes = append(changes, compareValues(keyPath, o, n)...)
+				}
+			}
+			return changes
+		}
+	case []interface{}:
+		if newValue, ok := new.([]interface{}); ok {
+			var changes []Change
+			for i := 0; i < len(oldValue) || i < len(newValue); i++ {
+				indexPath := path + "/" + strconv.Itoa(i)
+				switch {
+				case i >= len(oldValue):
+					changes = append(changes, Change{Path: indexPath, Kind: Added, New: newValue[i]})
+				case i >= len(newValue):
+					changes = append(changes, Change{Path: indexPath, Kind: Removed, Old: oldValue[i]})
+				default:
+					changes = append(changes, compareValues(indexPath, oldValue[i], newValue[i])...)
+				}
+			}
+			return changes
+		}
+	}
+
+	if reflect.DeepEqual(old, new) {
+		return nil
+	}
+	return []Change{{Path: path, Kind: Modified, Old: old, New: new}}
+}
@@ -0,0 +1,53 @@
+package doc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_CompareDocuments(t *testing.T) {
+	old := `openapi: 3.0.3
+components:
+  schemas:
+    Item:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+    Old/Item:
+      type: object
+`
+	new := `{
+  "components": {
+    "schemas": {
+      "Item": {
+        "properties": {
+          "name": {"type": "integer"},
+          "id": {"type": "string"}
+        },
+        "required": ["id", "name"],
+        "type": "object"
+      }
+    }
+  },
+  "openapi": "3.0.3"
+}`
+
+	changes, err := CompareDocuments([]byte(old), []byte(new))
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "/components/schemas/Item/properties/name/type", Kind: Modified, Old: "string", New: "integer"},
+		{Path: "/components/schemas/Item/required/1", Kind: Added, New: "name"},
+		{Path: "/components/schemas/Old~1Item", Kind: Removed, Old: map[string]interface{}{"type": "object"}},
+	}, changes)
+	assert.Equal(t, `~ /components/schemas/Item/properties/name/type: "string" -> "integer"`, changes[0].String())
+	assert.Equal(t, `+ /components/schemas/Item/required/1: "name"`, changes[1].String())
+	assert.Equal(t, `- /components/schemas/Old~1Item`, changes[2].String())
+
+	changes, err = CompareDocuments([]byte(new), []byte(new))
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
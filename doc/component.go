@@ -0,0 +1,143 @@
+package doc
+
+import (
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	"sort"
+)
+
+// ComponentName returns the key of the schema in the components of a document. References of the generator
+// use the name of the Go type, which is the ID of generated schemas or known from the x-go-type extension.
+func ComponentName(schema spec.Schema) string {
+	if goType, ok := schema.Extensions.GetString(GoTypeExtension); ok && len(goType) > 0 {
+		return goTypeName(goType)
+	}
+
+	return schema.ID
+}
+
+// componentSchema returns the schema as written to the components of a document. Since id is no keyword
+// of OpenAPI schemas, an ID differing from the component name becomes the title if there is none.
+func componentSchema(schema spec.Schema) spec.Schema {
+	if name := ComponentName(schema); schema.ID != name && len(schema.Title) == 0 {
+		schema.Title = schema.ID
+	}
+	schema.ID = ""
+
+	// copy the extensions to not modify the given schema when adding extensions
+	if schema.Extensions != nil {
+		extensions := make(spec.Extensions, len(schema.Extensions))
+		for key, value := range schema.Extensions {
+			extensions[key] = value
+		}
+		schema.Extensions = extensions
+	}
+
+	return schema
+}
+
+// componentSchemas returns the schemas as written to the components of a document by their sorted component names
+func componentSchemas(schemas []spec.Schema) ([]string, map[string]spec.Schema) {
+	components := make(map[string]spec.Schema)
+	var names []string
+	for _, schema := range schemas {
+		name := ComponentName(schema)
+		if _, exists := components[name]; !exists {
+			names = append(names, name)
+		}
+		components[name] = componentSchema(schema)
+	}
+	sort.Strings(names)
+
+	return names, components
+}
+
+// discriminatorPlaceholder holds the discriminator object of OpenAPI 3 while decoding a spec.Schema, whose
+// discriminator is the property name of Swagger 2
+const discriminatorPlaceholder = "$discriminator"
+
+// unmarshalSchema decodes a JSON schema keeping the discriminator objects of OpenAPI 3 in the extra properties
+func unmarshalSchema(raw []byte) (spec.Schema, error) {
+	var schema spec.Schema
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return schema, err
+	}
+	renameDiscriminators(value)
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return schema, err
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return schema, err
+	}
+	restoreDiscriminators(&schema)
+
+	return schema, nil
+}
+
+// renameDiscriminators moves the discriminator objects of the generic JSON schema and its subschemas to the
+// placeholder key. Keys of properties named discriminator are kept.
+func renameDiscriminators(value interface{}) {
+	schema, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if discriminator, isObject := schema["discriminator"].(map[string]interface{}); isObject {
+		delete(schema, "discriminator")
+		schema[discriminatorPlaceholder] = discriminator
+	}
+
+	for _, key := range []string{"properties", "patternProperties", "definitions"} {
+		if subschemas, ok := schema[key].(map[string]interface{}); ok {
+			for _, subschema := range subschemas {
+				renameDiscriminators(subschema)
+			}
+		}
+	}
+	for _, key := range []string{"items", "allOf", "oneOf", "anyOf"} {
+		if subschemas, ok := schema[key].([]interface{}); ok {
+			for _, subschema := range subschemas {
+				renameDiscriminators(subschema)
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		renameDiscriminators(schema[key])
+	}
+}
+
+// restoreDiscriminators moves the placeholders of renameDiscriminators back to the discriminator extra property
+func restoreDiscriminators(schema *spec.Schema) {
+	if discriminator, exists := schema.ExtraProps[discriminatorPlaceholder]; exists {
+		delete(schema.ExtraProps, discriminatorPlaceholder)
+		schema.ExtraProps["discriminator"] = discriminator
+	}
+
+	for _, subschemas := range []spec.SchemaProperties{schema.Properties, schema.PatternProperties, spec.SchemaProperties(schema.Definitions)} {
+		for name, subschema := range subschemas {
+			restoreDiscriminators(&subschema)
+			subschemas[name] = subschema
+		}
+	}
+	if schema.Items != nil {
+		if schema.Items.Schema != nil {
+			restoreDiscriminators(schema.Items.Schema)
+		}
+		for i := range schema.Items.Schemas {
+			restoreDiscriminators(&schema.Items.Schemas[i])
+		}
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		restoreDiscriminators(schema.AdditionalProperties.Schema)
+	}
+	if schema.Not != nil {
+		restoreDiscriminators(schema.Not)
+	}
+	for _, subschemas := range [][]spec.Schema{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for i := range subschemas {
+			restoreDiscriminators(&subschemas[i])
+		}
+	}
+}
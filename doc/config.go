@@ -0,0 +1,424 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ConfigFileName is the name of the config file discovered upwards from the working directory
+const ConfigFileName = "gostruct2openapi.yaml"
+
+// ErrConfigNotFound is returned by FindConfig if no config file exists
+var ErrConfigNotFound = errors.New(ConfigFileName + " not found")
+
+// GeneratorConfig holds the generator settings which can be set globally, per output and per package.
+// Component names are not configurable, they are the names of the Go types.
+type GeneratorConfig struct {
+	// Tag is the struct tag used to name properties
+	Tag string `yaml:"tag"`
+	// Filter is the regular expression used to filter struct names
+	Filter string `yaml:"filter"`
+	// Exclude is the regular expression used to exclude struct names
+	Exclude string `yaml:"exclude"`
+	// Embedded is either flatten or compose, see EmbeddedStructMode
+	Embedded string `yaml:"embedded"`
+	// Variants are the schema variants derived for each struct, e.g. Create or Patch
+	Variants []SchemaVariant `yaml:"variants"`
+	// Types maps fully qualified Go types to fixed schemas
+	Types map[string]TypeMapping `yaml:"types"`
+	// Examples sets a synthesized example on each schema without @example, unset values are inherited
+	Examples *bool `yaml:"examples"`
+	// ExampleFunctions uses the JSON output of Example functions in the test files as examples, unset values are inherited
+	ExampleFunctions *bool `yaml:"exampleFunctions"`
+}
+
+// merge returns the settings overridden by all non-empty settings of override
+func (g GeneratorConfig) merge(override GeneratorConfig) GeneratorConfig {
+	merged := g
+	if len(override.Tag) > 0 {
+		merged.Tag = override.Tag
+	}
+	if len(override.Filter) > 0 {
+		merged.Filter = override.Filter
+	}
+	if len(override.Exclude) > 0 {
+		merged.Exclude = override.Exclude
+	}
+	if len(override.Embedded) > 0 {
+		merged.Embedded = override.Embedded
+	}
+	if override.Variants != nil {
+		merged.Variants = override.Variants
+	}
+	if override.Examples != nil {
+		merged.Examples = override.Examples
+	}
+	if override.ExampleFunctions != nil {
+		merged.ExampleFunctions = override.ExampleFunctions
+	}
+	if len(override.Types) > 0 {
+		merged.Types = make(map[string]TypeMapping)
+		for goType, mapping := range g.Types {
+			merged.Types[goType] = mapping
+		}
+		for goType, mapping := range override.Types {
+			merged.Types[goType] = mapping
+		}
+	}
+
+	return merged
+}
+
+// newGenerator returns a Generator configured by the settings
+func (g GeneratorConfig) newGenerator(opts ...Option) (Generator, error) {
+	filter := ".*"
+	if len(g.Filter) > 0 {
+		filter = g.Filter
+	}
+	filterRegexp, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	if len(g.Exclude) > 0 {
+		exclude, err := regexp.Compile(g.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude: %w", err)
+		}
+		opts = append(opts, WithExclude(exclude))
+	}
+
+	switch g.Embedded {
+	case "", "flatten":
+	case "compose":
+		opts = append(opts, WithEmbeddedStructMode(ComposeEmbeddedStructs))
+	default:
+		return nil, fmt.Errorf("unknown embedded mode %q, expected flatten or compose", g.Embedded)
+	}
+
+	for _, variant := range g.Variants {
+		switch variant {
+		case CreateVariant, UpdateVariant, PatchVariant:
+		default:
+			return nil, fmt.Errorf("unknown variant %q, expected %s, %s or %s", variant, CreateVariant, UpdateVariant, PatchVariant)
+		}
+	}
+	if len(g.Variants) > 0 {
+		opts = append(opts, WithSchemaVariants(g.Variants...))
+	}
+
+	for goType, mapping := range g.Types {
+		opts = append(opts, WithTypeMapping(goType, mapping))
+	}
+	if g.Examples != nil && *g.Examples {
+		opts = append(opts, WithExamples())
+	}
+	if g.ExampleFunctions != nil && *g.ExampleFunctions {
+		opts = append(opts, WithExampleFunctions())
+	}
+
+	return NewOpenapiGenerator(filterRegexp, g.Tag, opts...), nil
+}
+
+// OutputConfig describes a document written by a generator run
+type OutputConfig struct {
+	GeneratorConfig `yaml:",inline"`
+	// Name identifies the output, e.g. to only generate a subset of the outputs
+	Name string `yaml:"name"`
+	// Packages are the packages to scan, relative patterns are resolved in the directory of the config file
+	Packages []string `yaml:"packages"`
+	// Output is the file the document is written to, relative to the directory of the config file
+	Output string `yaml:"output"`
+	// Format is json or yaml, defaults to the extension of Output
+	Format string `yaml:"format"`
+	// Merge merges the generated schemas into the existing document of Output instead of overwriting it
+	Merge bool `yaml:"merge"`
+	// Prune removes previously generated schemas which are no longer generated when merging
+	Prune bool `yaml:"prune"`
+	// Overlay writes an OpenAPI Overlay adding the generated schemas instead of a document
+	Overlay bool `yaml:"overlay"`
+	// Base is the existing document the overlay is computed against, relative to the directory of the config file
+	Base string `yaml:"base"`
+	// OpenAPI is the OpenAPI version of the document
+	OpenAPI string `yaml:"openapi"`
+	// Info is the info object of the document
+	Info Info `yaml:"info"`
+}
+
+// Config is the declarative project configuration of the generator
+type Config struct {
+	GeneratorConfig `yaml:",inline"`
+	// OpenAPI is the default OpenAPI version of all outputs
+	OpenAPI string `yaml:"openapi"`
+	// Info is the default info object of all outputs
+	Info Info `yaml:"info"`
+	// Packages holds generator settings overriding the output settings for a package pattern
+	Packages map[string]GeneratorConfig `yaml:"packages"`
+	// Outputs are the documents to generate
+	Outputs []OutputConfig `yaml:"outputs"`
+	// Cache is the directory of the cache reusing the schemas of unchanged packages, relative to the directory of the
+	// config file. No cache is used if empty.
+	Cache string `yaml:"cache"`
+
+	dir string
+}
+
+// FindConfig returns the path of the config file in dir or the nearest parent directory
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrConfigNotFound
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig loads the config file of the given path. If path is empty the config file is discovered
+// upwards from the working directory.
+func LoadConfig(path string) (*Config, error) {
+	if len(path) == 0 {
+		found, err := FindConfig(".")
+		if err != nil {
+			return nil, err
+		}
+		path = found
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if config.dir, err = filepath.Abs(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
+	return config, config.validate()
+}
+
+func (c *Config) validate() error {
+	if len(c.Outputs) == 0 {
+		return errors.New("config has no outputs")
+	}
+
+	names := make(map[string]struct{})
+	for i, output := range c.Outputs {
+		if len(output.Name) == 0 {
+			return fmt.Errorf("output %d has no name", i)
+		}
+		if _, exists := names[output.Name]; exists {
+			return fmt.Errorf("output %q is defined twice", output.Name)
+		}
+		names[output.Name] = struct{}{}
+		if len(output.Packages) == 0 {
+			return fmt.Errorf("output %q has no packages", output.Name)
+		}
+		if output.Merge && len(output.Output) == 0 {
+			return fmt.Errorf("output %q merges but has no output file", output.Name)
+		}
+		if output.Merge && output.Overlay {
+			return fmt.Errorf("output %q cannot both merge and write an overlay", output.Name)
+		}
+		if len(output.Base) > 0 && !output.Overlay {
+			return fmt.Errorf("output %q has a base but writes no overlay", output.Name)
+		}
+		if len(output.Format) > 0 {
+			if _, err := ParseFormat(output.Format); err != nil {
+				return fmt.Errorf("output %q: %w", output.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Dir returns the directory of the config file
+func (c *Config) Dir() string {
+	return c.dir
+}
+
+// SelectOutputs returns the outputs with the given names or all outputs if no name is given
+func (c *Config) SelectOutputs(names ...string) ([]OutputConfig, error) {
+	if len(names) == 0 {
+		return c.Outputs, nil
+	}
+
+	var outputs []OutputConfig
+	for _, name := range names {
+		found := false
+		for _, output := range c.Outputs {
+			if output.Name == name {
+				outputs = append(outputs, output)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown output %q", name)
+		}
+	}
+
+	return outputs, nil
+}
+
+// OutputPath returns the absolute path of the output file or an empty string if the output has no file
+func (c *Config) OutputPath(output OutputConfig) string {
+	return c.Path(output.Output)
+}
+
+// Path resolves the path relative to the directory of the config file, an empty path stays empty
+func (c *Config) Path(path string) string {
+	if len(path) == 0 || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(c.dir, path)
+}
+
+// OutputFormat returns the format of the output
+func (c *Config) OutputFormat(output OutputConfig) Format {
+	if format, err := ParseFormat(output.Format); err == nil {
+		return format
+	}
+
+	return FormatFromPath(output.Output)
+}
+
+// GenerateSchemas generates the schemas of the output. Packages sharing the same effective settings
+// are generated together, packages with overrides by their own generator.
+// The schemas are marked with the x-go-type extension to be keyed by their Go type in documents.
+func (c *Config) GenerateSchemas(output OutputConfig, opts ...Option) ([]spec.Schema, error) {
+	return c.generateSchemas(output, func(pkg string) string { return pkg }, opts...)
+}
+
+// GeneratePatternSchemas generates the schemas of packages matched by a package pattern of the output, e.g. of a
+// single package of ./..., with the settings of the pattern. To get the same schemas as GenerateSchemas the
+// patterns of PatternScope are loaded WithScope.
+func (c *Config) GeneratePatternSchemas(output OutputConfig, pattern string, packages []string, opts ...Option) ([]spec.Schema, error) {
+	subset := output
+	subset.Packages = packages
+	return c.generateSchemas(subset, func(string) string { return pattern }, opts...)
+}
+
+// PatternScope returns the package patterns of the output generated together with the pattern by GenerateSchemas,
+// i.e. the patterns with the same effective settings
+func (c *Config) PatternScope(output OutputConfig, pattern string) ([]string, error) {
+	_, key, err := c.effectiveSettings(output, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var scope []string
+	for _, other := range output.Packages {
+		_, otherKey, err := c.effectiveSettings(output, other)
+		if err != nil {
+			return nil, err
+		}
+		if otherKey == key {
+			scope = append(scope, other)
+		}
+	}
+	return scope, nil
+}
+
+// effectiveSettings returns the settings of the packages of the pattern and their key, the packages of patterns
+// with the same key are generated together
+func (c *Config) effectiveSettings(output OutputConfig, pattern string) (GeneratorConfig, string, error) {
+	effective := c.GeneratorConfig.merge(output.GeneratorConfig)
+	if override, exists := c.Packages[pattern]; exists {
+		effective = effective.merge(override)
+	}
+	key, err := json.Marshal(effective)
+
+	return effective, string(key), err
+}
+
+// generateSchemas generates the schemas of the output, the overrides of a package are looked up by its pattern
+func (c *Config) generateSchemas(output OutputConfig, pattern func(pkg string) string, opts ...Option) ([]spec.Schema, error) {
+	var groupKeys []string
+	groups := make(map[string][]string)
+	groupSettings := make(map[string]GeneratorConfig)
+	for _, pkg := range output.Packages {
+		effective, key, err := c.effectiveSettings(output, pattern(pkg))
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := groups[key]; !exists {
+			groupKeys = append(groupKeys, key)
+			groupSettings[key] = effective
+		}
+		groups[key] = append(groups[key], pkg)
+	}
+
+	defaults := []Option{WithDir(c.dir), WithGoTypeExtension()}
+	if len(c.Cache) > 0 {
+		defaults = append(defaults, WithCache(NewCache(c.Path(c.Cache))))
+	}
+	registry := make(SpecRegistry)
+	for _, key := range groupKeys {
+		generator, err := groupSettings[key].newGenerator(append(append([]Option{}, defaults...), opts...)...)
+		if err != nil {
+			return nil, fmt.Errorf("output %q: %w", output.Name, err)
+		}
+		schemas, err := generator.DocumentStruct(groups[key]...)
+		if err != nil {
+			return nil, fmt.Errorf("output %q: %w", output.Name, err)
+		}
+		for _, schema := range schemas {
+			registry.AddSchema(ComponentName(schema), schema)
+		}
+	}
+
+	return registry.Values(), nil
+}
+
+// GenerateDocument generates the document of the output
+func (c *Config) GenerateDocument(output OutputConfig, opts ...Option) (*Document, error) {
+	schemas, err := c.GenerateSchemas(output, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewDocument(output, schemas)
+}
+
+// NewDocument returns the document of the output holding the given schemas
+func (c *Config) NewDocument(output OutputConfig, schemas []spec.Schema) (*Document, error) {
+	openapiVersion := c.OpenAPI
+	if len(output.OpenAPI) > 0 {
+		openapiVersion = output.OpenAPI
+	}
+	info := c.Info
+	if len(output.Info.Title) > 0 {
+		info.Title = output.Info.Title
+	}
+	if len(output.Info.Description) > 0 {
+		info.Description = output.Info.Description
+	}
+	if len(output.Info.Version) > 0 {
+		info.Version = output.Info.Version
+	}
+
+	return NewDocument(openapiVersion, info, schemas)
+}
@@ -0,0 +1,106 @@
+package doc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_LoadConfig(t *testing.T) {
+	config, err := LoadConfig("testdata/config/gostruct2openapi.yaml")
+	assert.NoError(t, err)
+
+	outputs, err := config.SelectOutputs()
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 2)
+
+	outputs, err = config.SelectOutputs("structs")
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 1)
+	assert.Equal(t, filepath.Join(config.Dir(), "structs.yaml"), config.OutputPath(outputs[0]))
+	assert.Equal(t, YAMLFormat, config.OutputFormat(outputs[0]))
+
+	specs, err := config.GenerateSchemas(outputs[0])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TestStruct1", "TestStruct4"}, schemaIDs(specs))
+
+	_, err = config.SelectOutputs("unknown")
+	assert.Error(t, err)
+}
+
+func Test_Config_PackageOverride(t *testing.T) {
+	config, err := LoadConfig("testdata/config/gostruct2openapi.yaml")
+	assert.NoError(t, err)
+
+	outputs, err := config.SelectOutputs("other")
+	assert.NoError(t, err)
+
+	document, err := config.GenerateDocument(outputs[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "3.1.0", document.OpenAPI)
+	assert.Equal(t, Info{Title: "Other API", Version: "2.0.0"}, document.Info)
+	assert.Len(t, document.Components.Schemas, 3)
+	assert.Contains(t, document.Components.Schemas, "TestStruct1")
+	assert.Contains(t, document.Components.Schemas, "TestOtherStruct5")
+	assert.Contains(t, document.Components.Schemas, "TestOtherUnderlyingStruct")
+
+	// the override of the pattern applies to the packages it matches
+	schemas, err := config.GeneratePatternSchemas(outputs[0], "../../../testdata", []string{"github.com/mrahbar/gostruct2openapi/testdata"})
+	assert.NoError(t, err)
+	var names []string
+	for _, schema := range schemas {
+		names = append(names, ComponentName(schema))
+	}
+	assert.ElementsMatch(t, []string{"TestOtherStruct5", "TestOtherUnderlyingStruct"}, names)
+
+	// patterns with different settings are generated separately
+	scope, err := config.PatternScope(outputs[0], "../../../testdata")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"../../../testdata"}, scope)
+	scope, err = config.PatternScope(outputs[0], "..")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".."}, scope)
+}
+
+func Test_GeneratorConfig_Merge(t *testing.T) {
+	enabled, disabled := true, false
+	global := GeneratorConfig{Tag: "json", Examples: &enabled, ExampleFunctions: &enabled}
+
+	merged := global.merge(GeneratorConfig{Tag: "yaml"})
+	assert.Equal(t, "yaml", merged.Tag)
+	assert.True(t, *merged.Examples)
+	assert.True(t, *merged.ExampleFunctions)
+
+	// an explicit false overrides an inherited true
+	merged = global.merge(GeneratorConfig{Examples: &disabled, ExampleFunctions: &disabled})
+	assert.Equal(t, "json", merged.Tag)
+	assert.False(t, *merged.Examples)
+	assert.False(t, *merged.ExampleFunctions)
+}
+
+func Test_FindConfig(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "b")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+
+	_, err := FindConfig(dir)
+	assert.ErrorIs(t, err, ErrConfigNotFound)
+
+	expected := filepath.Join(filepath.Dir(dir), ConfigFileName)
+	assert.NoError(t, os.WriteFile(expected, []byte("outputs: []"), 0644))
+	path, err := FindConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, path)
+}
+
+func Test_LoadConfig_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+
+	assert.NoError(t, os.WriteFile(path, []byte("unknown: true"), 0644))
+	_, err := LoadConfig(path)
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(path, []byte("outputs: [{name: a}]"), 0644))
+	_, err = LoadConfig(path)
+	assert.EqualError(t, err, `output "a" has no packages`)
+}
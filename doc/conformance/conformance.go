@@ -0,0 +1,318 @@
+package conformance
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"github.com/mrahbar/gostruct2openapi/doc/validation"
+	"math"
+	"math/rand"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// maxDepth limits the nesting of generated values, deeper pointers, slices and maps are nil or empty
+const maxDepth = 5
+
+// Option configures optional behaviour of Check
+type Option func(c *checker)
+
+// WithSeed sets the seed of the random values to reproduce a failed check, by default the seed is random
+// and logged on failures
+func WithSeed(seed int64) Option {
+	return func(c *checker) {
+		c.seed = seed
+	}
+}
+
+// WithIterations sets the number of random values checked per type, defaults to 20
+func WithIterations(iterations int) Option {
+	return func(c *checker) {
+		c.iterations = iterations
+	}
+}
+
+// WithImplementations sets the implementations used for fields of the interface type, which is given as
+// nil pointer, e.g. WithImplementations((*Shape)(nil), Circle{}, &Square{}). Without implementations
+// interface fields are nil.
+func WithImplementations(iface interface{}, implementations ...interface{}) Option {
+	return func(c *checker) {
+		t := reflect.TypeOf(iface).Elem()
+		for _, implementation := range implementations {
+			c.implementations[t] = append(c.implementations[t], reflect.TypeOf(implementation))
+		}
+	}
+}
+
+// WithGenerator generates the values of the type of sample by the function, e.g. for types with a custom
+// JSON encoding whose fields cannot be randomized
+func WithGenerator(sample interface{}, generate func(r *rand.Rand) interface{}) Option {
+	return func(c *checker) {
+		c.generators[reflect.TypeOf(sample)] = generate
+	}
+}
+
+// WithNonNil generates no nil pointers, slices, maps and interfaces, which encoding/json writes as null
+func WithNonNil() Option {
+	return func(c *checker) {
+		c.nonNil = true
+	}
+}
+
+// WithValidatorOptions configures the validator of the encoded values, e.g. validation.WithNullForOptional()
+func WithValidatorOptions(opts ...validation.Option) Option {
+	return func(c *checker) {
+		c.validatorOptions = append(c.validatorOptions, opts...)
+	}
+}
+
+// builtinGenerators generate valid values of types whose fields are unexported or whose encoding is restricted
+var builtinGenerators = map[reflect.Type]func(r *rand.Rand) interface{}{
+	reflect.TypeOf(time.Time{}): func(r *rand.Rand) interface{} {
+		return time.Unix(r.Int63n(1<<32), r.Int63n(int64(time.Second))).UTC()
+	},
+	reflect.TypeOf(net.IP{}): func(r *rand.Rand) interface{} {
+		return net.IP(randomAddr(r).AsSlice())
+	},
+	reflect.TypeOf(netip.Addr{}): func(r *rand.Rand) interface{} {
+		return randomAddr(r)
+	},
+	reflect.TypeOf(netip.Prefix{}): func(r *rand.Rand) interface{} {
+		addr := randomAddr(r)
+		return netip.PrefixFrom(addr, r.Intn(addr.BitLen()+1)).Masked()
+	},
+}
+
+// randomAddr returns an IPv4 or IPv6 address
+func randomAddr(r *rand.Rand) netip.Addr {
+	if r.Intn(2) == 0 {
+		var ip [4]byte
+		r.Read(ip[:])
+		return netip.AddrFrom4(ip)
+	}
+	var ip [16]byte
+	r.Read(ip[:])
+	return netip.AddrFrom16(ip)
+}
+
+type checker struct {
+	seed             int64
+	iterations       int
+	nonNil           bool
+	implementations  map[reflect.Type][]reflect.Type
+	generators       map[reflect.Type]func(r *rand.Rand) interface{}
+	validatorOptions []validation.Option
+	random           *rand.Rand
+}
+
+// Check is a test helper asserting that the JSON encoding of the Go types of the samples matches their schemas.
+// The schemas must be generated WithGoTypeExtension to map each sample to its schema. For every sample type
+// random values are generated via reflection, encoded by encoding/json and validated. A mismatch fails the test
+// with the offending value and the JSON pointers of the violations.
+func Check(t testing.TB, schemas []spec.Schema, samples []interface{}, opts ...Option) {
+	t.Helper()
+
+	c := &checker{
+		seed:            time.Now().UnixNano(),
+		iterations:      20,
+		implementations: make(map[reflect.Type][]reflect.Type),
+		generators:      make(map[reflect.Type]func(r *rand.Rand) interface{}),
+	}
+	for goType, generate := range builtinGenerators {
+		c.generators[goType] = generate
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.random = rand.New(rand.NewSource(c.seed))
+
+	validator, err := validation.New(schemas, c.validatorOptions...)
+	if err != nil {
+		t.Fatalf("invalid schemas: %v", err)
+	}
+	components := make(map[string]string)
+	for _, schema := range schemas {
+		if goType, ok := schema.Extensions.GetString(doc.GoTypeExtension); ok {
+			components[goType] = doc.ComponentName(schema)
+		}
+	}
+
+	for _, sample := range samples {
+		sampleType := reflect.TypeOf(sample)
+		for sampleType.Kind() == reflect.Ptr {
+			sampleType = sampleType.Elem()
+		}
+		goType := sampleType.PkgPath() + "." + sampleType.Name()
+		component, exists := components[goType]
+		if !exists {
+			t.Errorf("no schema with %s %s, generate the schemas WithGoTypeExtension", doc.GoTypeExtension, goType)
+			continue
+		}
+
+		for i := 0; i < c.iterations; i++ {
+			if !c.check(t, validator, component, sampleType) {
+				t.Logf("reproduce with conformance.WithSeed(%d)", c.seed)
+				break
+			}
+		}
+	}
+}
+
+// check validates a random value of the type and returns whether it matches the schema
+func (c *checker) check(t testing.TB, validator *validation.Validator, component string, goType reflect.Type) bool {
+	t.Helper()
+
+	value := reflect.New(goType).Elem()
+	c.fill(value, 0)
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		t.Errorf("%s cannot be encoded: %v", goType, err)
+		return false
+	}
+
+	err = validator.Validate(component, data)
+	var violations validation.Errors
+	switch {
+	case errors.As(err, &violations):
+		var lines []string
+		for _, violation := range violations {
+			lines = append(lines, "  "+violation.Error())
+		}
+		t.Errorf("JSON of %s does not match schema %s:\n%s\nvalue: %s", goType, component, strings.Join(lines, "\n"), data)
+		return false
+	case err != nil:
+		t.Errorf("validating %s: %v", goType, err)
+		return false
+	}
+
+	return true
+}
+
+// fill sets the settable value to a random value of its type
+func (c *checker) fill(value reflect.Value, depth int) {
+	if generate, exists := c.generators[value.Type()]; exists {
+		value.Set(reflect.ValueOf(generate(c.random)))
+		return
+	}
+
+	r := c.random
+	switch value.Kind() {
+	case reflect.Bool:
+		value.SetBool(r.Intn(2) == 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		max := int64(math.MaxInt64 >> (64 - value.Type().Bits()))
+		value.SetInt(r.Int63n(max) - r.Int63n(max))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value.SetUint(r.Uint64() >> (64 - value.Type().Bits()))
+	case reflect.Float32:
+		value.SetFloat(float64(float32(r.NormFloat64() * math.Pow10(r.Intn(8)))))
+	case reflect.Float64:
+		value.SetFloat(r.NormFloat64() * math.Pow10(r.Intn(16)))
+	case reflect.String:
+		value.SetString(c.randomString())
+	case reflect.Ptr:
+		if c.isNil(depth) {
+			return
+		}
+		pointer := reflect.New(value.Type().Elem())
+		c.fill(pointer.Elem(), depth+1)
+		value.Set(pointer)
+	case reflect.Slice:
+		if c.isNil(depth) {
+			return
+		}
+		length := c.length(depth)
+		slice := reflect.MakeSlice(value.Type(), length, length)
+		for i := 0; i < slice.Len(); i++ {
+			c.fill(slice.Index(i), depth+1)
+		}
+		value.Set(slice)
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			c.fill(value.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if c.isNil(depth) {
+			return
+		}
+		m := reflect.MakeMap(value.Type())
+		for i, length := 0, c.length(depth); i < length; i++ {
+			key := reflect.New(value.Type().Key()).Elem()
+			c.fill(key, depth+1)
+			element := reflect.New(value.Type().Elem()).Elem()
+			c.fill(element, depth+1)
+			m.SetMapIndex(key, element)
+		}
+		value.Set(m)
+	case reflect.Struct:
+		c.fillStruct(value, depth)
+	case reflect.Interface:
+		implementations := c.implementations[value.Type()]
+		if len(implementations) == 0 || c.isNil(depth) {
+			return
+		}
+		implementation := implementations[r.Intn(len(implementations))]
+		element := reflect.New(implementation).Elem()
+		c.fill(element, depth+1)
+		value.Set(element)
+	}
+}
+
+// fillStruct sets the exported fields of a struct, structs with a custom encoding but only unexported fields,
+// e.g. big.Int, keep their zero value. String fields tagged as constant, e.g. `openapi:"const=circle"`, are set to
+// their constant.
+func (c *checker) fillStruct(value reflect.Value, depth int) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		if constant, tagged := constantOf(value.Type().Field(i).Tag); tagged && field.Kind() == reflect.String {
+			field.SetString(constant)
+			continue
+		}
+		c.fill(field, depth+1)
+	}
+}
+
+// constantOf returns the constant of a field given by the const option of the openapi struct tag
+func constantOf(tag reflect.StructTag) (string, bool) {
+	for _, option := range strings.Split(tag.Get("openapi"), ",") {
+		if strings.HasPrefix(option, "const=") {
+			return strings.TrimPrefix(option, "const="), true
+		}
+	}
+	return "", false
+}
+
+// isNil decides whether a pointer, slice, map or interface is nil
+func (c *checker) isNil(depth int) bool {
+	if depth >= maxDepth {
+		return true
+	}
+	return !c.nonNil && c.random.Intn(4) == 0
+}
+
+// length returns the length of a slice or map, nested values get shorter to limit the size of the value
+func (c *checker) length(depth int) int {
+	if depth >= maxDepth-1 {
+		return 0
+	}
+	return c.random.Intn(4)
+}
+
+// randomString returns a string of up to 12 characters, including characters encoding/json escapes
+func (c *checker) randomString() string {
+	const characters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -_<>&\"\\/äß€"
+	runes := []rune(characters)
+	result := make([]rune, c.random.Intn(13))
+	for i := range result {
+		result[i] = runes[c.random.Intn(len(runes))]
+	}
+	return string(result)
+}
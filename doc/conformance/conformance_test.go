@@ -0,0 +1,77 @@
+package conformance
+
+import (
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"github.com/mrahbar/gostruct2openapi/doc/testdata"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+// recorder records the failures of Check
+type recorder struct {
+	testing.TB
+	errors []string
+	logs   []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Logf(format string, args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprintf(format, args...))
+}
+
+func generate(t *testing.T, filter string) []spec.Schema {
+	generator := doc.NewOpenapiGenerator(regexp.MustCompile(filter), "json", doc.WithGoTypeExtension())
+	schemas, err := generator.DocumentStruct("../testdata")
+	assert.NoError(t, err)
+	return schemas
+}
+
+func Test_Check(t *testing.T) {
+	schemas := generate(t, "^(TestItem|TestCircle|TestSquare|TestBaseStruct|TestTypeMappingStruct)$")
+	samples := []interface{}{testdata.TestItem{}, testdata.TestCircle{}, &testdata.TestSquare{}, testdata.TestBaseStruct{}}
+	Check(t, schemas, samples, WithSeed(1), WithIterations(50))
+	Check(t, schemas, []interface{}{testdata.TestTypeMappingStruct{}}, WithSeed(1), WithIterations(50), WithNonNil())
+}
+
+func Test_Check_Mismatch(t *testing.T) {
+	schemas := generate(t, "^TestItem$")
+	for i, schema := range schemas {
+		if doc.ComponentName(schema) == "TestItem" {
+			name := schema.Properties["name"]
+			name.Type = spec.StringOrArray{"integer"}
+			schemas[i].Properties["name"] = name
+		}
+	}
+
+	r := &recorder{TB: t}
+	Check(r, schemas, []interface{}{testdata.TestItem{}, testdata.TestCircle{}}, WithSeed(1))
+	if assert.Len(t, r.errors, 2) {
+		assert.Regexp(t, `^JSON of testdata.TestItem does not match schema TestItem:\n  /name: expected integer but got string\nvalue: \{"id":`, r.errors[0])
+		assert.Equal(t, "no schema with x-go-type github.com/mrahbar/gostruct2openapi/doc/testdata.TestCircle, generate the schemas WithGoTypeExtension", r.errors[1])
+	}
+	assert.Equal(t, []string{"reproduce with conformance.WithSeed(1)"}, r.logs)
+}
+
+func Test_Check_Nil(t *testing.T) {
+	schemas := generate(t, "^TestPolymorphicStruct$")
+
+	// interfaces without implementations are encoded as null, which their schemas accept
+	Check(t, schemas, []interface{}{testdata.TestPolymorphicStruct{}}, WithSeed(1), WithIterations(1))
+
+	// constant fields are set to the value of their tag
+	Check(t, schemas, []interface{}{testdata.TestPolymorphicStruct{}}, WithSeed(1), WithIterations(10), WithNonNil(),
+		WithImplementations((*testdata.TestShape)(nil), testdata.TestCircle{}, &testdata.TestSquare{}),
+		WithImplementations((*testdata.TestAnnotatedInterface)(nil), testdata.TestCircle{}))
+}
@@ -0,0 +1,176 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultOpenAPIVersion is the OpenAPI version of a Document if none is given
+	DefaultOpenAPIVersion = "3.0.3"
+	defaultInfoTitle      = "API"
+	defaultInfoVersion    = "1.0.0"
+)
+
+// Format is the serialization format of a Document
+type Format string
+
+const (
+	JSONFormat Format = "json"
+	YAMLFormat Format = "yaml"
+)
+
+// ParseFormat returns the Format for the given name, e.g. json, yaml or yml
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSONFormat, nil
+	case "yaml", "yml":
+		return YAMLFormat, nil
+	}
+
+	return "", fmt.Errorf("unknown format %q, expected json or yaml", name)
+}
+
+// FormatFromPath returns the Format derived from the extension of the given path, defaults to JSONFormat
+func FormatFromPath(path string) Format {
+	if format, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), ".")); err == nil {
+		return format
+	}
+
+	return JSONFormat
+}
+
+// Info is the info object of a Document
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// Components holds the generated schemas of a Document
+type Components struct {
+	Schemas map[string]spec.Schema `json:"schemas"`
+}
+
+// Document is an OpenAPI document containing the generated schemas as components
+type Document struct {
+	OpenAPI    string                 `json:"openapi"`
+	Info       Info                   `json:"info"`
+	Paths      map[string]interface{} `json:"paths"`
+	Components Components             `json:"components"`
+}
+
+// NewDocument returns a new Document for the given OpenAPI version and info with the schemas registered
+// by their ComponentName. Default values are used for an empty version, title or info version.
+func NewDocument(openapiVersion string, info Info, schemas []spec.Schema) (*Document, error) {
+	if len(openapiVersion) == 0 {
+		openapiVersion = DefaultOpenAPIVersion
+	}
+	if !strings.HasPrefix(openapiVersion, "3.0.") && !strings.HasPrefix(openapiVersion, "3.1.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, expected 3.0.x or 3.1.x", openapiVersion)
+	}
+	if len(info.Title) == 0 {
+		info.Title = defaultInfoTitle
+	}
+	if len(info.Version) == 0 {
+		info.Version = defaultInfoVersion
+	}
+
+	document := &Document{
+		OpenAPI: openapiVersion,
+		Info:    info,
+		Paths:   make(map[string]interface{}),
+	}
+	_, document.Components.Schemas = componentSchemas(schemas)
+
+	return document, nil
+}
+
+// ParseDocumentSchemas returns the component schemas of a YAML or JSON OpenAPI document ordered by name.
+// The ID of each schema is set to its component name.
+func ParseDocumentSchemas(content []byte) ([]spec.Schema, error) {
+	document, err := parseNode(content)
+	if err != nil {
+		return nil, err
+	}
+	components := mappingValue(mappingValue(document.Content[0], "components"), "schemas")
+	if components == nil {
+		return nil, nil
+	}
+
+	registry := make(SpecRegistry)
+	for i := 0; i+1 < len(components.Content); i += 2 {
+		var out bytes.Buffer
+		if err := writeNodeJSON(&out, components.Content[i+1], "", ""); err != nil {
+			return nil, err
+		}
+		schema, err := unmarshalSchema(out.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", components.Content[i].Value, err)
+		}
+		schema.ID = components.Content[i].Value
+		registry.AddSchema(schema.ID, schema)
+	}
+
+	return registry.Values(), nil
+}
+
+// Encode returns the pretty printed document in the given format. The output is deterministic,
+// i.e. object keys are sorted.
+func (d *Document) Encode(format Format) ([]byte, error) {
+	return encode(d, format)
+}
+
+// Write writes the pretty printed document in the given format to w
+func (d *Document) Write(w io.Writer, format Format) error {
+	out, err := d.Encode(format)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// ConvertDocument encodes the YAML or JSON document in the given format keeping the key order, e.g. of a
+// hand-written document the schemas were merged into
+func ConvertDocument(content []byte, format Format) ([]byte, error) {
+	node, err := parseNode(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeNode(node, format)
+}
+
+// encode marshals the value to indented JSON. YAML is derived from the JSON to keep the key order
+// and to honour the custom JSON marshalling of spec.Schema.
+func encode(v interface{}, format Format) ([]byte, error) {
+	if format == YAMLFormat {
+		node, err := toNode(v)
+		if err != nil {
+			return nil, err
+		}
+		return encodeNode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}, format)
+	}
+	if format != JSONFormat {
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
@@ -0,0 +1,159 @@
+package doc
+
+import (
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Document_Encode(t *testing.T) {
+	schemas := []spec.Schema{
+		{SchemaProps: spec.SchemaProps{ID: "Test B", Type: []string{"object"}, Properties: spec.SchemaProperties{
+			"b": {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+			"a": {SchemaProps: spec.SchemaProps{Type: []string{"integer"}}},
+		}}, VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{GoTypeExtension: "example.com/model.B"}}},
+		{SchemaProps: spec.SchemaProps{ID: "A", Type: []string{"object"}}},
+	}
+	document, err := NewDocument("", Info{Title: "Test"}, schemas)
+	assert.NoError(t, err)
+
+	out, err := document.Encode(JSONFormat)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Test",
+    "version": "1.0.0"
+  },
+  "paths": {},
+  "components": {
+    "schemas": {
+      "A": {
+        "type": "object"
+      },
+      "B": {
+        "type": "object",
+        "title": "Test B",
+        "properties": {
+          "a": {
+            "type": "integer"
+          },
+          "b": {
+            "type": "string"
+          }
+        },
+        "x-go-type": "example.com/model.B"
+      }
+    }
+  }
+}
+`, string(out))
+
+	out, err = document.Encode(YAMLFormat)
+	assert.NoError(t, err)
+	assert.Equal(t, `openapi: 3.0.3
+info:
+  title: Test
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    A:
+      type: object
+    B:
+      type: object
+      title: Test B
+      properties:
+        a:
+          type: integer
+        b:
+          type: string
+      x-go-type: example.com/model.B
+`, string(out))
+}
+
+func Test_Document_UnsupportedVersion(t *testing.T) {
+	_, err := NewDocument("2.0", Info{}, nil)
+	assert.Error(t, err)
+}
+
+func Test_ParseFormat(t *testing.T) {
+	format, err := ParseFormat("yml")
+	assert.NoError(t, err)
+	assert.Equal(t, YAMLFormat, format)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func Test_ConvertDocument(t *testing.T) {
+	content := []byte(`openapi: 3.0.3
+info:
+  title: Test
+paths:
+  /items:
+    get:
+      responses:
+        "200":
+          description: Items
+`)
+	out, err := ConvertDocument(content, JSONFormat)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Test"
+  },
+  "paths": {
+    "/items": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "Items"
+          }
+        }
+      }
+    }
+  }
+}
+`, string(out))
+
+	out, err = ConvertDocument(out, YAMLFormat)
+	assert.NoError(t, err)
+	assert.YAMLEq(t, string(content), string(out))
+
+	_, err = ConvertDocument([]byte(`[]`), JSONFormat)
+	assert.Error(t, err)
+}
+
+func Test_ParseDocumentSchemas_Discriminator(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"discriminator": {"type": "object", "properties": {"kind": {"type": "string"}}},
+			"shape": {
+				"oneOf": [{"$ref": "#/components/schemas/Circle"}],
+				"discriminator": {"propertyName": "kind", "mapping": {"circle": "#/components/schemas/Circle"}}
+			},
+			"shapes": {
+				"type": "array",
+				"items": {"oneOf": [{"$ref": "#/components/schemas/Circle"}], "discriminator": {"propertyName": "kind"}}
+			}
+		}
+	}`
+	schemas, err := ParseDocumentSchemas([]byte(`{"openapi": "3.1.0", "components": {"schemas": {"Drawing": ` + schema + `}}}`))
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 1)
+	assert.Contains(t, schemas[0].Properties, "discriminator")
+	assert.NotContains(t, schemas[0].Properties, discriminatorPlaceholder)
+
+	// the property and the discriminator objects are kept through a round trip
+	schemas[0].ID = ""
+	out, err := json.Marshal(schemas[0])
+	assert.NoError(t, err)
+	assert.JSONEq(t, schema, string(out))
+	copied, err := unmarshalSchema(out)
+	assert.NoError(t, err)
+	assert.Equal(t, schemas[0], copied)
+}
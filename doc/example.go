@@ -0,0 +1,392 @@
+package doc
+
+import (
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// formatExamples are the examples of strings of well-known formats
+var formatExamples = map[string]string{
+	"date-time": "2024-01-02T15:04:05Z",
+	"RFC3339":   "2024-01-02T15:04:05Z",
+	"date":      "2024-01-02",
+	"time":      "15:04:05",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"ip":        "192.0.2.1",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"cidr":      "192.0.2.0/24",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"byte":      "ZXhhbXBsZQ==",
+	"password":  "secret",
+}
+
+// SynthesizeExamples returns an example value for each schema by its ComponentName. Explicit examples, e.g. of
+// @example, are used as they are, otherwise values are derived from const, default, enum, format, pattern and the
+// numeric and length constraints. References are followed, a reference back to a schema which is already being
+// synthesized is omitted to terminate cycles.
+func SynthesizeExamples(schemas []spec.Schema) (map[string]interface{}, error) {
+	components, err := schemaValues(schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &synthesizer{components: components, visiting: make(map[string]bool)}
+	examples := make(map[string]interface{}, len(components))
+	for name, component := range components {
+		s.visiting[name] = true
+		if example, ok := s.example(component); ok {
+			examples[name] = example
+		}
+		s.visiting[name] = false
+	}
+
+	return examples, nil
+}
+
+// SynthesizeExample returns an example value of a generic JSON schema, e.g. of a response of a parsed document,
+// whose references are resolved in the given component schemas
+func SynthesizeExample(schema map[string]interface{}, components map[string]interface{}) interface{} {
+	s := &synthesizer{components: make(map[string]map[string]interface{}, len(components)), visiting: make(map[string]bool)}
+	for name, component := range components {
+		s.components[name] = jsonschema.AsObject(component)
+	}
+
+	example, _ := s.example(schema)
+	return example
+}
+
+// addExamples sets the synthesized example on every schema without example
+func addExamples(schemas []spec.Schema) ([]spec.Schema, error) {
+	examples, err := SynthesizeExamples(schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range schemas {
+		if example, exists := examples[ComponentName(schemas[i])]; exists && schemas[i].Example == nil {
+			schemas[i].Example = example
+		}
+	}
+
+	return schemas, nil
+}
+
+type synthesizer struct {
+	components map[string]map[string]interface{}
+	// visiting holds the components being synthesized to omit cyclic references
+	visiting map[string]bool
+}
+
+// example returns the example of the schema, false if the value must be omitted to terminate a cycle
+func (s *synthesizer) example(schema map[string]interface{}) (interface{}, bool) {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := jsonschema.UnescapePointer(jsonschema.RefName(ref))
+		component, exists := s.components[name]
+		if !exists || s.visiting[name] {
+			return nil, false
+		}
+		s.visiting[name] = true
+		defer func() { s.visiting[name] = false }()
+		return s.example(component)
+	}
+
+	for _, keyword := range []string{"const", "example", "default"} {
+		if value, exists := schema[keyword]; exists {
+			return value, true
+		}
+	}
+	if examples := jsonschema.AsArray(schema["examples"]); len(examples) > 0 {
+		return examples[0], true
+	}
+	if enum := jsonschema.AsArray(schema["enum"]); len(enum) > 0 {
+		return enum[0], true
+	}
+
+	if allOf := jsonschema.AsArray(schema["allOf"]); len(allOf) > 0 {
+		return s.allOfExample(schema, allOf)
+	}
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if subschemas := jsonschema.AsArray(schema[keyword]); len(subschemas) > 0 {
+			return s.oneOfExample(schema, subschemas)
+		}
+	}
+
+	switch jsonschema.Type(schema) {
+	case "string":
+		return stringExample(schema), true
+	case "integer":
+		return integerExample(schema), true
+	case "number":
+		return numberExample(schema), true
+	case "boolean":
+		return true, true
+	case "array":
+		return s.arrayExample(schema), true
+	case "object":
+		return s.objectExample(schema), true
+	}
+
+	if _, hasProperties := schema["properties"]; hasProperties {
+		return s.objectExample(schema), true
+	}
+	return map[string]interface{}{}, true
+}
+
+// allOfExample merges the examples of all subschemas and the own properties of the schema
+func (s *synthesizer) allOfExample(schema map[string]interface{}, allOf []interface{}) (interface{}, bool) {
+	merged := make(map[string]interface{})
+	var last interface{}
+	for _, subschema := range allOf {
+		example, ok := s.example(jsonschema.AsObject(subschema))
+		if !ok {
+			return nil, false
+		}
+		if object, isObject := example.(map[string]interface{}); isObject {
+			for key, value := range object {
+				merged[key] = value
+			}
+		} else {
+			last = example
+		}
+	}
+	if _, hasProperties := schema["properties"]; hasProperties {
+		for key, value := range s.objectExample(schema) {
+			merged[key] = value
+		}
+	}
+
+	if len(merged) == 0 && last != nil {
+		return last, true
+	}
+	return merged, true
+}
+
+// oneOfExample returns the example of the first subschema which is not omitted. With a discriminator
+// the discriminator property is set to the value mapped to the chosen subschema.
+func (s *synthesizer) oneOfExample(schema map[string]interface{}, subschemas []interface{}) (interface{}, bool) {
+	for _, subschema := range subschemas {
+		example, ok := s.example(jsonschema.AsObject(subschema))
+		if !ok {
+			continue
+		}
+
+		discriminator := jsonschema.AsObject(schema["discriminator"])
+		object, isObject := example.(map[string]interface{})
+		propertyName, _ := discriminator["propertyName"].(string)
+		if ref, _ := jsonschema.AsObject(subschema)["$ref"].(string); isObject && len(propertyName) > 0 && len(ref) > 0 {
+			discriminated := map[string]interface{}{propertyName: jsonschema.DiscriminatorValue(discriminator, ref)}
+			for key, value := range object {
+				if key != propertyName {
+					discriminated[key] = value
+				}
+			}
+			return discriminated, true
+		}
+		return example, true
+	}
+
+	return nil, false
+}
+
+func (s *synthesizer) arrayExample(schema map[string]interface{}) []interface{} {
+	items, ok := schema["items"].(map[string]interface{})
+	if !ok {
+		return []interface{}{}
+	}
+	item, ok := s.example(items)
+	if !ok {
+		return []interface{}{}
+	}
+
+	count := 1
+	if min, ok := jsonschema.Number(schema["minItems"]); ok && min > 1 {
+		if unique, _ := schema["uniqueItems"].(bool); !unique {
+			count = int(min)
+		}
+	}
+	if max, ok := jsonschema.Number(schema["maxItems"]); ok && max < float64(count) {
+		count = int(max)
+	}
+
+	array := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		array = append(array, item)
+	}
+	return array
+}
+
+func (s *synthesizer) objectExample(schema map[string]interface{}) map[string]interface{} {
+	object := make(map[string]interface{})
+	for name, property := range jsonschema.AsObject(schema["properties"]) {
+		if example, ok := s.example(jsonschema.AsObject(property)); ok {
+			object[name] = example
+		}
+	}
+
+	if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok && len(object) == 0 {
+		if example, ok := s.example(additional); ok {
+			object["key"] = example
+		}
+	}
+	return object
+}
+
+func stringExample(schema map[string]interface{}) string {
+	if format, ok := schema["format"].(string); ok {
+		if example, exists := formatExamples[format]; exists {
+			return example
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if example, ok := patternExample(pattern); ok {
+			return example
+		}
+	}
+
+	example := "string"
+	if min, ok := jsonschema.Number(schema["minLength"]); ok && float64(len(example)) < min {
+		example += strings.Repeat("x", int(min)-len(example))
+	}
+	if max, ok := jsonschema.Number(schema["maxLength"]); ok && float64(len(example)) > max {
+		example = example[:int(max)]
+	}
+	return example
+}
+
+func integerExample(schema map[string]interface{}) int64 {
+	return int64(math.Round(numericExample(schema, 1, 1)))
+}
+
+func numberExample(schema map[string]interface{}) float64 {
+	return numericExample(schema, 1.5, 0.5)
+}
+
+// numericExample returns the value moved into the bounds of the schema, step is the distance kept to exclusive
+// bounds
+func numericExample(schema map[string]interface{}, value, step float64) float64 {
+	if min, ok := jsonschema.Number(schema["minimum"]); ok && value < min {
+		value = min
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive {
+			value += step
+		}
+	}
+	if min, ok := jsonschema.Number(schema["exclusiveMinimum"]); ok && value <= min {
+		value = min + step
+	}
+	if max, ok := jsonschema.Number(schema["maximum"]); ok && value > max {
+		value = max
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive {
+			value -= step
+		}
+	}
+	if max, ok := jsonschema.Number(schema["exclusiveMaximum"]); ok && value >= max {
+		value = max - step
+	}
+	if multipleOf, ok := jsonschema.Number(schema["multipleOf"]); ok && multipleOf > 0 {
+		rounded := math.Ceil(value/multipleOf) * multipleOf
+		if !belowMaximum(schema, rounded) {
+			// round down instead to keep the value within the maximum
+			rounded = math.Floor(value/multipleOf) * multipleOf
+		}
+		value = rounded
+	}
+
+	return value
+}
+
+// belowMaximum reports whether the value does not exceed the maximum of the schema
+func belowMaximum(schema map[string]interface{}, value float64) bool {
+	if max, ok := jsonschema.Number(schema["maximum"]); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); value > max || exclusive && value >= max {
+			return false
+		}
+	}
+	if max, ok := jsonschema.Number(schema["exclusiveMaximum"]); ok && value >= max {
+		return false
+	}
+
+	return true
+}
+
+// patternExample returns the shortest string matching the simple regular expression, false for patterns
+// whose example does not match, e.g. with lookarounds or conflicting anchors
+func patternExample(pattern string) (string, bool) {
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if !writePattern(&b, parsed.Simplify()) {
+		return "", false
+	}
+
+	example := b.String()
+	if compiled, err := regexp.Compile(pattern); err != nil || !compiled.MatchString(example) {
+		return "", false
+	}
+	return example, true
+}
+
+func writePattern(b *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		r, ok := classRune(re.Rune)
+		if !ok {
+			return false
+		}
+		b.WriteRune(r)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune('x')
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary, syntax.OpStar, syntax.OpQuest:
+		// zero repetitions are the shortest match
+	case syntax.OpCapture, syntax.OpPlus:
+		return writePattern(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			if !writePattern(b, re.Sub[0]) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writePattern(b, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return writePattern(b, re.Sub[0])
+	default:
+		return false
+	}
+
+	return true
+}
+
+// classRune returns a readable rune of the ranges of a character class, preferring letters and digits
+func classRune(ranges []rune) (rune, bool) {
+	var printable rune = -1
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r < ranges[i]+256; r++ {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r, true
+			}
+			if printable < 0 && unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				printable = r
+			}
+		}
+	}
+
+	return printable, printable >= 0
+}
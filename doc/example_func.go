@@ -0,0 +1,64 @@
+package doc
+
+import (
+	"encoding/json"
+	"go/doc"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"strings"
+	"unicode"
+)
+
+// functionExamples returns the JSON output of the Example functions in the test files of the packages by the ID of
+// the struct they are named after, e.g. ExampleOrder or ExampleOrder_json for Order. Examples of methods, e.g.
+// ExampleOrder_Total, and outputs which are no JSON are skipped. Of several examples of a struct the first by name
+// is used.
+func (o *openapiGenerator) functionExamples(pkgs []*packages.Package) map[string]interface{} {
+	examples := make(map[string]interface{})
+
+	paths := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		paths = append(paths, pkg.Types.Path())
+	}
+	files, err := loadTestFiles(o.dir, paths...)
+	if err != nil {
+		o.logger.Printf("Skipping example functions: %v\n", err)
+		return examples
+	}
+
+	for _, pkg := range pkgs {
+		for _, example := range doc.Examples(files[pkg.Types.Path()]...) {
+			typeName, suffix, _ := strings.Cut(example.Name, "_")
+			if len(suffix) > 0 && unicode.IsUpper([]rune(suffix)[0]) {
+				continue
+			}
+			object, ok := pkg.Types.Scope().Lookup(typeName).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			if _, isStruct := object.Type().Underlying().(*types.Struct); !isStruct {
+				continue
+			}
+
+			if len(strings.TrimSpace(example.Output)) == 0 {
+				continue
+			}
+			id := pkg.Types.Path() + "." + typeName
+			if _, exists := examples[id]; exists {
+				continue
+			}
+			var value interface{}
+			if err := json.Unmarshal([]byte(example.Output), &value); err != nil {
+				o.logger.Printf("Skipping example function Example%s: output is no JSON: %v\n", example.Name, err)
+				continue
+			}
+			if _, isObject := value.(map[string]interface{}); !isObject {
+				o.logger.Printf("Skipping example function Example%s: output is no JSON object\n", example.Name)
+				continue
+			}
+			examples[id] = value
+		}
+	}
+
+	return examples
+}
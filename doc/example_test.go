@@ -0,0 +1,140 @@
+package doc
+
+import (
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc/testdata"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func Test_SynthesizeExamples(t *testing.T) {
+	item := spec.Schema{SchemaProps: spec.SchemaProps{
+		ID:       "Item",
+		Type:     []string{"object"},
+		Required: []string{"id"},
+		Properties: map[string]spec.Schema{
+			"id":       *spec.StrFmtProperty("uuid"),
+			"code":     *spec.StringProperty().WithPattern(`^[A-Z]{3}-\d{2,4}$`),
+			"short":    *spec.StringProperty().WithMaxLength(3),
+			"long":     *spec.StringProperty().WithMinLength(8),
+			"status":   *spec.StringProperty().WithEnum("open", "closed"),
+			"quantity": *spec.Int32Property().WithMinimum(10, false).WithMaximum(20, false),
+			"price":    *spec.Float64Property().WithMinimum(0, true).WithMultipleOf(0.25),
+			"ratio":    *spec.Float64Property().WithMaximum(1.2, false).WithMultipleOf(1),
+			"tags":     *spec.ArrayProperty(spec.StringProperty()).WithMinItems(2),
+			"labels":   *spec.MapProperty(spec.BoolProperty()),
+			"parent":   *spec.RefSchema("#/components/schemas/Item"),
+			"shape": {SchemaProps: spec.SchemaProps{
+				OneOf: []spec.Schema{*spec.RefSchema("#/components/schemas/Circle")},
+			}, ExtraProps: map[string]interface{}{
+				"discriminator": map[string]interface{}{"propertyName": "kind", "mapping": map[string]interface{}{"circle": "#/components/schemas/Circle"}},
+			}},
+		},
+	}}
+	circle := spec.Schema{SchemaProps: spec.SchemaProps{
+		ID:   "Circle",
+		Type: []string{"object"},
+		Properties: map[string]spec.Schema{
+			"kind":   *spec.StringProperty(),
+			"radius": *spec.Float64Property(),
+		},
+	}}
+
+	examples, err := SynthesizeExamples([]spec.Schema{item, circle})
+	assert.NoError(t, err)
+	bytes, err := json.Marshal(examples)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Circle": {"kind": "string", "radius": 1.5},
+		"Item": {
+			"id": "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			"code": "AAA-00",
+			"short": "str",
+			"long": "stringxx",
+			"status": "open",
+			"quantity": 10,
+			"price": 1.5,
+			"ratio": 1,
+			"tags": ["string", "string"],
+			"labels": {"key": true},
+			"shape": {"kind": "circle", "radius": 1.5}
+		}
+	}`, string(bytes))
+}
+
+func Test_OpenapiGenerator_Examples(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile("^TestExample"), "json", WithGoTypeExtension(), WithExamples())
+	specs, err := generator.DocumentStruct("./testdata")
+	assert.NoError(t, err)
+	assert.Empty(t, generator.Diagnostics())
+
+	examples := make(map[string]interface{})
+	for _, schema := range specs {
+		examples[ComponentName(schema)] = schema.Example
+	}
+	bytes, err := json.Marshal(examples)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"TestExampleStruct": {"name": "Widget", "size": 3},
+		"TestExampleNode": {"name": "string", "children": []},
+		"TestUnderlyingStruct": {"UnderlyingFieldB": "string", "UnderlyingFieldC": 1.5, "UnderlyingFieldD": true}
+	}`, string(bytes))
+
+	// the schemas are sorted by ID: TestExampleNode, TestExampleStruct, TestUnderlyingStruct
+	bytes, err = json.Marshal(specs[1].Properties["owner"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"description": "Owner comment",
+		"allOf": [{"$ref": "#/components/schemas/TestUnderlyingStruct"}],
+		"example": {"UnderlyingFieldB": "owner"}
+	}`, string(bytes))
+	assert.Equal(t, "Widget", specs[1].Properties["name"].Example)
+	assert.Equal(t, float64(3), specs[1].Properties["size"].Example)
+}
+
+func Test_OpenapiGenerator_LiteralExamples(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile("^TestLiteral"), "json", WithGoTypeExtension())
+	specs, err := generator.DocumentStruct("./testdata")
+	assert.NoError(t, err)
+
+	examples := make(map[string]interface{})
+	for _, schema := range specs {
+		examples[ComponentName(schema)] = schema.Example
+	}
+	assert.Nil(t, examples["TestLiteralItem"])
+	assert.Nil(t, examples["TestLiteralAudit"])
+
+	// the example equals the encoding of the variable by encoding/json
+	expected, err := json.Marshal(testdata.TestLiteralOrderExample)
+	assert.NoError(t, err)
+	actual, err := json.Marshal(examples["TestLiteralOrder"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+
+	// an embedded struct with tag name is not promoted
+	expected, err = json.Marshal(testdata.TestLiteralRevisionExample)
+	assert.NoError(t, err)
+	actual, err = json.Marshal(examples["TestLiteralRevision"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func Test_OpenapiGenerator_ExampleFunctions(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile("^Test(Output|Literal)"), "json", WithExampleFunctions())
+	specs, err := generator.DocumentStruct("./testdata")
+	assert.NoError(t, err)
+
+	examples := make(map[string]interface{})
+	for _, schema := range specs {
+		examples[ComponentName(schema)] = schema.Example
+	}
+	assert.Equal(t, map[string]interface{}{"name": "Widget", "tags": []interface{}{"new"}}, examples["TestOutputStruct"])
+	// the output of ExampleTestLiteralItem is no JSON
+	assert.Nil(t, examples["TestLiteralItem"])
+	// the output of ExampleTestLiteralAudit is no JSON object
+	assert.Nil(t, examples["TestLiteralAudit"])
+	// the variable TestLiteralOrderExample takes precedence
+	assert.Equal(t, "o-1", examples["TestLiteralOrder"].(map[string]interface{})["id"])
+}
@@ -0,0 +1,34 @@
+package doc
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file by writing a temporary file in the same directory and renaming it, so readers
+// never see a partially written file and a failed write keeps the previous content
+func WriteFileAtomic(path string, out []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(out); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Chmod(mode); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -1,77 +1,377 @@
 package doc
 
 import (
-	"fmt"
 	"github.com/go-openapi/spec"
 	"github.com/mrahbar/gostruct2openapi/doc/internal"
 	"github.com/mrahbar/gostruct2openapi/doc/internal/util"
 	"go/types"
 	"golang.org/x/tools/go/packages"
+	"log"
+	"os"
 	"regexp"
+	"runtime"
+	"sort"
+	"sync"
 )
 
 const defaultStructTag = "json"
 
-// Generator generated the OpenAPI document for the named packages
+// Generator generated the OpenAPI document for the named packages. It is safe for concurrent use, the packages of a
+// DocumentStruct call are traversed concurrently, see WithWorkers. Each call is independent of the others unless
+// the calls share a Session, see WithSession. Types must not be registered in the TypeRegistry during a call.
 type Generator interface {
 	DocumentStruct(_package ...string) ([]spec.Schema, error)
+	// TypeRegistry returns the registry mapping Go types to fixed schemas, which can be used to register custom types
+	TypeRegistry() *TypeRegistry
+	// Diagnostics returns the issues found by validating the schemas of the last DocumentStruct call
+	Diagnostics() []Diagnostic
 }
 
 type openapiGenerator struct {
-	filter           *regexp.Regexp
-	structTag        string
-	commentRegistry  *internal.CommentRegistry
-	metadataParser   *internal.MetadataParser
-	processedTargets map[string]struct{}
+	filter         *regexp.Regexp
+	exclude        *regexp.Regexp
+	logger         *log.Logger
+	dir            string
+	structTag      string
+	metadataParser *internal.MetadataParser
+	// session is the shared session set by WithSession
+	session *Session
+	// the state of a DocumentStruct call is only set on the copy of the generator bound to its session
+	commentRegistry    *internal.CommentRegistry
+	processedTargets   map[string]struct{}
+	processedMethods   map[string]struct{}
+	packages           []*packages.Package
+	index              *packageIndex
+	typeRegistry       *TypeRegistry
+	embeddedStructMode EmbeddedStructMode
+	schemaVariants     []SchemaVariant
+	goTypeExtension    bool
+	examples           bool
+	exampleFunctions   bool
+	workers            int
+	cache              *Cache
+	// scope is set by WithScope
+	scope *Scope
+	// scannedPackages is set by a fork whose traversal looked up types in all loaded packages
+	scannedPackages bool
+	// mu guards the diagnostics, it is shared with the copies of the generator
+	mu          *sync.Mutex
+	diagnostics []Diagnostic
+	// sourceExamples holds the values of variables annotated with @example and the outputs of Example functions
+	// by the ID of their struct
+	sourceExamples map[string]interface{}
 }
 
 // NewOpenapiGenerator returns a new Generator
-func NewOpenapiGenerator(filter *regexp.Regexp, structTag string) Generator {
+func NewOpenapiGenerator(filter *regexp.Regexp, structTag string, opts ...Option) Generator {
 	if len(structTag) == 0 {
 		structTag = defaultStructTag
 	}
 
-	return &openapiGenerator{
-		filter:           filter,
-		structTag:        structTag,
-		commentRegistry:  internal.NewCommentRegistry(),
-		metadataParser:   internal.NewMetadataParser(),
-		processedTargets: make(map[string]struct{}),
+	generator := &openapiGenerator{
+		filter:         filter,
+		structTag:      structTag,
+		metadataParser: internal.NewMetadataParser(),
+		mu:             &sync.Mutex{},
+		typeRegistry:   NewTypeRegistry(),
+		logger:         log.New(os.Stderr, "", 0),
 	}
+	for _, opt := range opts {
+		opt(generator)
+	}
+
+	return generator
 }
 
 func (o *openapiGenerator) DocumentStruct(_package ...string) ([]spec.Schema, error) {
-	pkgs, err := loadPackages(_package...)
+	session := o.session
+	if session == nil {
+		session = NewSession()
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	bound := session.bind(o)
+	specs, err := bound.generate(_package)
 	if err != nil {
 		return nil, err
 	}
 
-	registry := o.parse(pkgs)
-	return registry.Values(), nil
+	schemas := specs.Values()
+	if o.examples {
+		if schemas, err = addExamples(schemas); err != nil {
+			return nil, err
+		}
+	}
+	session.record(bound, schemas)
+	diagnostics := ValidateSchemas(schemas)
+	o.mu.Lock()
+	o.diagnostics = diagnostics
+	o.mu.Unlock()
+
+	return schemas, nil
 }
 
-func (o *openapiGenerator) parse(pkgs []*packages.Package) SpecRegistry {
-	specs := make(SpecRegistry)
+func (o *openapiGenerator) TypeRegistry() *TypeRegistry {
+	return o.typeRegistry
+}
+
+func (o *openapiGenerator) Diagnostics() []Diagnostic {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.diagnostics
+}
+
+// packageResult holds the schemas generated by the traversal of a package and the types it processed
+type packageResult struct {
+	Schemas SpecRegistry
+	Targets []string
+	Methods []string
+	// Global is set if the schemas depend on all loaded packages, i.e. on interface implementations found in them
+	Global bool
+}
+
+// generate loads and traverses the packages. With a cache only packages without cached result are traversed,
+// if all results are cached the packages are not loaded at all. Packages of the scope are loaded but not traversed.
+func (o *openapiGenerator) generate(patterns []string) (SpecRegistry, error) {
+	loaded, documented, err := o.scoped(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys map[string]string
+	var rootsKey string
+	cached := make(map[string]*packageResult)
+	// calls of a shared session depend on the earlier calls, so their results are not cached
+	if o.cache != nil && o.session == nil {
+		if roots, err := loadPackageGraph(o.dir, loaded...); err == nil && !hasErrors(roots) {
+			keys, rootsKey, err = o.cacheKeys(roots)
+			if err != nil {
+				o.logger.Printf("Skipping cache: %v\n", err)
+			}
+			roots = documentedPackages(roots, documented)
+			results := make([]*packageResult, 0, len(roots))
+			for _, root := range roots {
+				if key, exists := keys[root.PkgPath]; exists {
+					if result := o.cache.load(key, rootsKey); result != nil {
+						cached[root.PkgPath] = result
+						results = append(results, result)
+					}
+				}
+			}
+			if len(roots) > 0 && len(results) == len(roots) {
+				o.logger.Printf("Using cached schemas of %d packages\n", len(roots))
+				o.recordScope(results)
+				return o.merge(results), nil
+			}
+		}
+	}
+
+	pkgs, err := loadPackages(o.dir, loaded...)
+	if err != nil {
+		return nil, err
+	}
+	o.prepare(pkgs)
+	pkgs = documentedPackages(pkgs, documented)
+	results := make([]*packageResult, len(pkgs))
+	for i, pkg := range pkgs {
+		results[i] = cached[pkg.PkgPath]
+	}
+	o.traverse(pkgs, results)
+
+	for i, pkg := range pkgs {
+		key, exists := keys[pkg.PkgPath]
+		if _, hit := cached[pkg.PkgPath]; hit || !exists {
+			continue
+		}
+		if err := o.cache.store(key, rootsKey, pkg.PkgPath, results[i]); err != nil {
+			o.logger.Printf("Caching schemas of %s failed: %v\n", pkg.PkgPath, err)
+		}
+	}
+	if len(cached) > 0 {
+		o.logger.Printf("Using cached schemas of %d packages\n", len(cached))
+	}
+
+	o.recordScope(results)
+	return o.merge(results), nil
+}
+
+// recordScope sets whether one of the results depends on all packages of the scope
+func (o *openapiGenerator) recordScope(results []*packageResult) {
+	if o.scope == nil {
+		return
+	}
+	o.scope.Global = false
+	for _, result := range results {
+		o.scope.Global = o.scope.Global || result.Global
+	}
+}
+
+// scoped returns the patterns to load, i.e. the patterns and the scope, and the paths of the documented packages.
+// Without scope all loaded packages are documented and the paths are nil.
+func (o *openapiGenerator) scoped(patterns []string) ([]string, map[string]struct{}, error) {
+	if o.scope == nil {
+		return patterns, nil, nil
+	}
+
+	paths, err := packagePaths(o.dir, patterns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	documented := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		documented[path] = struct{}{}
+	}
 
+	return append(append([]string{}, patterns...), o.scope.Patterns...), documented, nil
+}
+
+// documentedPackages returns the packages whose paths are documented, all packages if the paths are nil
+func documentedPackages(pkgs []*packages.Package, documented map[string]struct{}) []*packages.Package {
+	if documented == nil {
+		return pkgs
+	}
+
+	var filtered []*packages.Package
 	for _, pkg := range pkgs {
-		// prepare all comments in package
-		o.commentRegistry.Load(pkg)
+		if _, exists := documented[pkg.PkgPath]; exists {
+			filtered = append(filtered, pkg)
+		}
+	}
 
-		// for each package iterate all types (structs, (struct) methods, functions, ...)
-		scope := pkg.Types.Scope()
-		for _, structScopeName := range scope.Names() {
-			if o.doFilter(structScopeName) {
-				continue
+	return filtered
+}
+
+// prepare registers the loaded packages and collects the examples declared in them
+func (o *openapiGenerator) prepare(pkgs []*packages.Package) {
+	o.packages = append(o.packages, pkgs...)
+	o.index.add(pkgs...)
+	o.sourceExamples = o.literalExamples(pkgs)
+	if o.exampleFunctions {
+		for id, example := range o.functionExamples(pkgs) {
+			if _, exists := o.sourceExamples[id]; !exists {
+				o.sourceExamples[id] = example
 			}
-			specs.Extend(o.processObj(internal.NewTargetType(structScopeName, scope.Lookup(structScopeName))))
 		}
 	}
+}
+
+// traverse sets the missing results by traversing their packages. The packages are traversed concurrently by
+// forks of the generator, each tracking the processed types on its own.
+func (o *openapiGenerator) traverse(pkgs []*packages.Package, results []*packageResult) {
+	var missing []int
+	for i := range pkgs {
+		if results[i] == nil {
+			missing = append(missing, i)
+		}
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.workerCount(len(missing)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fork := o.fork()
+				results[i] = fork.result(fork.parsePackage(pkgs[i]))
+			}
+		}()
+	}
+	for _, i := range missing {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// merge returns the schemas of the results and records their processed types. Merging in package order keeps the
+// schema of the first package like a sequential traversal does, which would skip a type of the same name in a later
+// package.
+func (o *openapiGenerator) merge(results []*packageResult) SpecRegistry {
+	specs := make(SpecRegistry)
+	for _, result := range results {
+		for key, schema := range result.Schemas {
+			if _, exists := specs[key]; !exists {
+				specs[key] = schema
+			}
+		}
+		for _, name := range result.Targets {
+			o.processedTargets[name] = struct{}{}
+		}
+		for _, name := range result.Methods {
+			o.processedMethods[name] = struct{}{}
+		}
+	}
+
+	return specs
+}
+
+// parsePackage processes all structs of the package matched by the filter
+func (o *openapiGenerator) parsePackage(pkg *packages.Package) SpecRegistry {
+	specs := make(SpecRegistry)
+
+	// prepare all comments in package
+	o.commentRegistry.Load(pkg)
+
+	// for each package iterate all types (structs, (struct) methods, functions, ...)
+	scope := pkg.Types.Scope()
+	for _, structScopeName := range scope.Names() {
+		if o.doFilter(structScopeName) {
+			continue
+		}
+		specs.Extend(o.processObj(internal.NewTargetType(structScopeName, scope.Lookup(structScopeName))))
+	}
 
 	return specs
 }
 
+// fork returns a copy of the generator for the traversal of a package. The copy shares the loaded packages, comments
+// and examples, which are safe for concurrent use, and tracks the processed types separately.
+func (o *openapiGenerator) fork() *openapiGenerator {
+	fork := *o
+	fork.processedTargets = make(map[string]struct{}, len(o.processedTargets))
+	for name := range o.processedTargets {
+		fork.processedTargets[name] = struct{}{}
+	}
+	fork.processedMethods = make(map[string]struct{}, len(o.processedMethods))
+	for name := range o.processedMethods {
+		fork.processedMethods[name] = struct{}{}
+	}
+
+	return &fork
+}
+
+// result returns the result of the traversal of a package by the fork
+func (o *openapiGenerator) result(specs SpecRegistry) *packageResult {
+	result := &packageResult{Schemas: specs, Global: o.scannedPackages}
+	for name := range o.processedTargets {
+		result.Targets = append(result.Targets, name)
+	}
+	for name := range o.processedMethods {
+		result.Methods = append(result.Methods, name)
+	}
+	sort.Strings(result.Targets)
+	sort.Strings(result.Methods)
+
+	return result
+}
+
+// workerCount returns the number of goroutines traversing the packages
+func (o *openapiGenerator) workerCount(packages int) int {
+	workers := o.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > packages {
+		workers = packages
+	}
+
+	return workers
+}
+
 func (o *openapiGenerator) doFilter(value string) bool {
-	return !o.filter.MatchString(value)
+	return !o.filter.MatchString(value) || (o.exclude != nil && o.exclude.MatchString(value))
 }
 
 func (o *openapiGenerator) processObj(target *internal.TargetType) SpecRegistry {
@@ -96,29 +396,54 @@ func (o *openapiGenerator) processTarget(target *internal.TargetStruct) SpecRegi
 		o.processedTargets[target.Name()] = struct{}{}
 	}
 
-	fmt.Printf("Processing struct: name=%s\n", target.Name())
+	o.logger.Printf("Processing struct: name=%s\n", target.Name())
 
 	if target.IsNamedType() {
-		if pkgs, err := loadPackages(target.ToNamedType().Obj().Pkg().Path()); err == nil {
-			o.commentRegistry.Load(pkgs...)
-		}
+		o.loadComments(target.ToNamedType())
 	}
 
 	metadata := o.metadataParser.ParseStructDesc(o.commentRegistry.Lookup(target.ID()))
-	var props = spec.SchemaProps{ID: metadata.Lookup(internal.TitleAttr, target.Name()), Type: []string{internal.ObjectType.String()}, Description: util.CleanDescription(metadata.Lookup(internal.DescriptionAttr, "")), Properties: make(spec.SchemaProperties)}
-	specs.AddSchemaProp(props)
+	// the schema is keyed by the name of the Go type the references point at, @title only sets the title
+	name := goTypeName(target.ID())
+	var props = spec.SchemaProps{ID: name, Title: metadata.Lookup(internal.TitleAttr, ""), Type: []string{internal.ObjectType.String()}, Description: util.CleanDescription(metadata.Lookup(internal.DescriptionAttr, "")), Properties: make(spec.SchemaProperties)}
 	specs.Extend(o.toSpec(&props, target))
+	if len(props.AllOf) > 0 {
+		// composed struct: the own properties are appended to the referenced embedded structs
+		own := spec.Schema{SchemaProps: spec.SchemaProps{Type: props.Type, Properties: props.Properties}}
+		props.AllOf = append(props.AllOf, own)
+		props.Type = nil
+		props.Properties = nil
+	}
+	schema := spec.Schema{SchemaProps: props}
+	if example, exists := metadata[internal.ExampleAttr]; exists {
+		schema.Example = internal.ParseExample(example)
+	} else if example, exists := o.sourceExamples[target.ID()]; exists {
+		schema.Example = example
+	}
+	if o.goTypeExtension {
+		schema.AddExtension(GoTypeExtension, target.ID())
+	}
+	specs.AddSchema(props.ID, schema)
+	for _, variant := range o.schemaVariants {
+		derived := variant.derive(name, spec.Schema{SchemaProps: props})
+		specs.AddSchema(derived.ID, derived)
+	}
 
 	return specs
 }
 
 func (o *openapiGenerator) processStructMethods(_structTyp *types.Named) SpecRegistry {
 	specs := make(SpecRegistry)
+	// the receivers of the methods are of the struct type itself
+	if _, exists := o.processedMethods[_structTyp.String()]; exists {
+		return specs
+	}
+	o.processedMethods[_structTyp.String()] = struct{}{}
 
 	for i := 0; i < _structTyp.NumMethods(); i++ {
 		scope := _structTyp.Method(i).Scope()
 		if scope == nil {
-			fmt.Printf("Method %q of struct %s has no associated scope\n", _structTyp.Method(i).Name(), _structTyp.String())
+			o.logger.Printf("Method %q of struct %s has no associated scope\n", _structTyp.Method(i).Name(), _structTyp.String())
 			continue
 		}
 		for _, methodScopeName := range scope.Names() {
@@ -140,7 +465,13 @@ func (o *openapiGenerator) toSpec(props *spec.SchemaProps, target *internal.Targ
 
 		if field.Embedded() {
 			if _embeddedStruct, ok := field.Type().Underlying().(*types.Struct); ok {
-				subSpecs := o.toSpec(props, internal.NewTargetStruct(field.Name(), field.Type(), _embeddedStruct))
+				embedded := internal.NewTargetStruct(field.Name(), field.Type(), _embeddedStruct)
+				if o.embeddedStructMode == ComposeEmbeddedStructs && embedded.IsNamedType() {
+					props.AllOf = append(props.AllOf, internal.NewStructSpecField(field.Name()).ToSchema(""))
+					specs.Extend(o.processTarget(embedded))
+					continue
+				}
+				subSpecs := o.toSpec(props, embedded)
 				specs.Extend(subSpecs)
 			}
 		} else if field.Exported() {
@@ -154,9 +485,9 @@ func (o *openapiGenerator) toSpec(props *spec.SchemaProps, target *internal.Targ
 				fieldName,
 			)
 
-			//early handling of time.Time due to underlying type is actually a struct
-			if util.IsTimeField(field.Type()) {
-				tf.SetSpecField(internal.StructFieldTypeMap["time.Time"])
+			//early handling of registered types like time.Time whose underlying type does not match their encoding
+			if sf := o.typeRegistry.lookupType(field.Type()); sf != nil {
+				tf.SetSpecField(sf)
 				o.mapField(props, tf)
 				continue
 			}
@@ -167,7 +498,17 @@ func (o *openapiGenerator) toSpec(props *spec.SchemaProps, target *internal.Targ
 				tf.SetIsAdditionalProperties()
 				tf.SetElem(u.Elem())
 				specs.Extend(o.handleUnderlyingField(props, tf))
-			case *types.Interface, *types.Chan:
+			case *types.Interface:
+				if sf, subSpecs := o.processInterface(field.Type(), u, false); sf != nil {
+					tf.SetSpecField(sf)
+					o.mapField(props, tf)
+					specs.Extend(subSpecs)
+					continue
+				}
+				// falling back to object type because no implementation of the interface is known
+				tf.SetSpecField(internal.NewSpecField(internal.ObjectType))
+				o.mapField(props, tf)
+			case *types.Chan:
 				// falling back to object type because handling of type is not possible
 				tf.SetSpecField(internal.NewSpecField(internal.ObjectType))
 				o.mapField(props, tf)
@@ -180,12 +521,26 @@ func (o *openapiGenerator) toSpec(props *spec.SchemaProps, target *internal.Targ
 				tf.SetElem(u.Elem())
 				specs.Extend(o.handleUnderlyingField(props, tf))
 			case *types.Slice:
+				if sf, exists := internal.StructFieldTypeMap[u.String()]; exists {
+					tf.SetSpecField(sf)
+					o.mapField(props, tf)
+					continue
+				}
+				tf.SetElem(u.Elem())
+				tf.SetIsArrayType()
+				specs.Extend(o.handleUnderlyingField(props, tf))
+			case *types.Array:
 				tf.SetElem(u.Elem())
 				tf.SetIsArrayType()
 				specs.Extend(o.handleUnderlyingField(props, tf))
 			default:
-				tf.SetSpecField(internal.StructFieldTypeMap[underlying.String()])
-				o.mapField(props, tf)
+				if sf, exists := internal.StructFieldTypeMap[underlying.String()]; exists {
+					tf.SetSpecField(sf)
+					o.mapField(props, tf)
+					continue
+				}
+				tf.SetElem(field.Type())
+				o.handleUnknownField(props, tf)
 			}
 		}
 	}
@@ -196,6 +551,19 @@ func (o *openapiGenerator) toSpec(props *spec.SchemaProps, target *internal.Targ
 func (o *openapiGenerator) handleUnderlyingField(props *spec.SchemaProps, target *internal.TargetField) SpecRegistry {
 	specs := make(SpecRegistry)
 
+	if sf := o.typeRegistry.lookupType(target.Elem()); sf != nil {
+		if target.IsArrayType() {
+			sf = internal.NewArrayOfSpecField(sf)
+		}
+		if target.IsAdditionalProperties() {
+			target.SetAdditionalProperties(sf)
+		} else {
+			target.SetSpecField(sf)
+		}
+		o.mapField(props, target)
+		return specs
+	}
+
 	switch u := target.UnderlyingElem().(type) {
 	case *types.Pointer:
 		target.SetElem(u.Elem())
@@ -218,11 +586,13 @@ func (o *openapiGenerator) handleUnderlyingField(props *spec.SchemaProps, target
 		o.mapField(props, target)
 		specs.Extend(o.processTarget(internal.NewTargetStruct(name, field.Type(), u)))
 	case *types.Basic:
-		var sf *internal.SpecField
+		sf, exists := internal.StructFieldTypeMap[u.String()]
+		if !exists {
+			o.handleUnknownField(props, target)
+			break
+		}
 		if target.IsArrayType() {
-			sf = internal.NewArraySpecField(internal.StructFieldTypeMap[u.String()].BaseType())
-		} else {
-			sf = internal.StructFieldTypeMap[u.String()]
+			sf = internal.NewArrayOfSpecField(sf)
 		}
 		if target.IsAdditionalProperties() {
 			target.SetAdditionalProperties(sf)
@@ -230,13 +600,11 @@ func (o *openapiGenerator) handleUnderlyingField(props *spec.SchemaProps, target
 			target.SetSpecField(sf)
 		}
 		o.mapField(props, target)
-	default:
-		fmt.Printf("%s has no well-known basic type. Got %s\n", target.ID(), target.UnderlyingElem().String())
-		var sf *internal.SpecField
-		if target.IsArrayType() {
-			sf = internal.NewArraySpecField(internal.ObjectType)
-		} else {
-			sf = internal.NewSpecField(internal.ObjectType)
+	case *types.Interface:
+		sf, subSpecs := o.processInterface(target.Elem(), u, target.IsArrayType())
+		if sf == nil {
+			o.handleUnknownField(props, target)
+			break
 		}
 		if target.IsAdditionalProperties() {
 			target.SetAdditionalProperties(sf)
@@ -244,21 +612,50 @@ func (o *openapiGenerator) handleUnderlyingField(props *spec.SchemaProps, target
 			target.SetSpecField(sf)
 		}
 		o.mapField(props, target)
+		specs.Extend(subSpecs)
+	default:
+		o.handleUnknownField(props, target)
 	}
 
 	return specs
 }
 
-func (o *openapiGenerator) mapField(props *spec.SchemaProps, target *internal.TargetField) {
-	schema := spec.Schema{
-		SchemaProps: target.SpecField().ToSchemaProp(util.CleanDescription(o.commentRegistry.Lookup(target.ID()))),
+// handleUnknownField falls back to the object type for fields whose type cannot be mapped
+func (o *openapiGenerator) handleUnknownField(props *spec.SchemaProps, target *internal.TargetField) {
+	o.logger.Printf("%s has no well-known basic type. Got %s\n", target.ID(), target.UnderlyingElem().String())
+	var sf *internal.SpecField
+	if target.IsArrayType() {
+		sf = internal.NewArraySpecField(internal.ObjectType)
+	} else {
+		sf = internal.NewSpecField(internal.ObjectType)
 	}
+	if target.IsAdditionalProperties() {
+		target.SetAdditionalProperties(sf)
+	} else {
+		target.SetSpecField(sf)
+	}
+	o.mapField(props, target)
+}
+
+func (o *openapiGenerator) mapField(props *spec.SchemaProps, target *internal.TargetField) {
+	metadata := o.metadataParser.ParseStructDesc(o.commentRegistry.Lookup(target.ID()))
+	description := util.CleanDescription(metadata.Lookup(internal.DescriptionAttr, ""))
+	schema := target.SpecField().ToSchema(description)
 	if target.HasAdditionalProperties() {
+		additionalProperties := target.AdditionalProperties().ToSchema("")
 		schema.AdditionalProperties = &spec.SchemaOrBool{
-			Schema: &spec.Schema{
-				SchemaProps: target.AdditionalProperties().ToSchemaProp(""),
-			},
+			Schema: &additionalProperties,
 		}
 	}
-	props.Properties[target.CanonicalFieldName(o.structTag)] = schema
+
+	fieldName := target.CanonicalFieldName(o.structTag)
+	markers := internal.ParseFieldMarkers(metadata, target.TagOptions(internal.OpenapiTag))
+	markers.Apply(&schema, description)
+	if example, exists := metadata[internal.ExampleAttr]; exists {
+		internal.ApplyExample(&schema, description, internal.ParseExample(example))
+	}
+	if markers.Required && !util.Contains(props.Required, fieldName) {
+		props.Required = append(props.Required, fieldName)
+	}
+	props.Properties[fieldName] = schema
 }
@@ -1,9 +1,14 @@
 package doc
 
 import (
+	"bytes"
 	"encoding/json"
 	"github.com/go-openapi/spec"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 )
@@ -25,7 +30,8 @@ func Test_OpenapiGenerator_Struct0(t *testing.T) {
 	assert.NoError(t, err)
 	assert.JSONEq(t, `{
 		"description":"Test Struct 0 description",
-		"id": "Test Struct 0",
+		"id": "testStruct0",
+		"title": "Test Struct 0",
 		"type":"object",
 		"properties": {
 			"FieldB": {
@@ -51,7 +57,8 @@ func Test_OpenapiGenerator_Struct1(t *testing.T) {
 	assert.NoError(t, err)
 	assert.JSONEq(t, `{
 		"description":"Test Struct 1 description",
-		"id": "Test Struct 1",
+		"id": "TestStruct1",
+		"title": "Test Struct 1",
 		"type":"object",
 		"properties": {
 			"FieldB": {
@@ -81,7 +88,8 @@ func Test_OpenapiGenerator_Struct2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.JSONEq(t, `{
 		"description":"Test Struct 2 description",
-		"id": "Test Struct 2",
+		"id": "TestStruct2",
+		"title": "Test Struct 2",
 		"type":"object",
 		"properties": {
 			"BaseFieldB": {
@@ -122,6 +130,75 @@ func Test_OpenapiGenerator_Struct2(t *testing.T) {
 	assert.Empty(t, missingDescriptions(specs))
 }
 
+func Test_OpenapiGenerator_Struct2_ComposeEmbeddedStructs(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile("TestStruct2"), "json", WithEmbeddedStructMode(ComposeEmbeddedStructs))
+	specs, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
+	assert.NoError(t, err)
+	assert.Len(t, specs, 2)
+
+	bytes, err := json.Marshal(specs)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{
+			"description":"Test Base description",
+			"id": "TestBaseStruct",
+			"title": "Test Base Struct",
+			"type":"object",
+			"properties": {
+				"BaseFieldB": {
+					"description": "BaseFieldB comment",
+					"type": "string"
+				},
+				"BaseFieldC": {
+					"description": "BaseFieldC comment",
+					"type": "number"
+				},
+				"BaseFieldD": {
+					"description": "BaseFieldD comment",
+					"type": "boolean"
+				}
+			}
+		},
+		{
+			"description":"Test Struct 2 description",
+			"id": "TestStruct2",
+			"title": "Test Struct 2",
+			"allOf": [
+				{
+					"$ref": "#/components/schemas/TestBaseStruct"
+				},
+				{
+					"type":"object",
+					"properties": {
+						"FieldB": {
+							"description": "FieldB comment",
+							"items": {
+								"type": "string"
+							},
+							"type": "array"
+						},
+						"FieldC": {
+							"description": "FieldC comment",
+							"items": {
+								"type": "integer"
+							},
+							"type": "array"
+						},
+						"FieldD": {
+							"description": "FieldD comment",
+							"items": {
+								"type": "boolean"
+							},
+							"type": "array"
+						}
+					}
+				}
+			]
+		}
+	]`, string(bytes))
+	assert.Empty(t, missingDescriptions(specs))
+}
+
 func Test_OpenapiGenerator_Struct3(t *testing.T) {
 	generator := NewOpenapiGenerator(regexp.MustCompile("TestStruct3"), "json")
 	specs, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
@@ -133,7 +210,8 @@ func Test_OpenapiGenerator_Struct3(t *testing.T) {
 	assert.JSONEq(t, `[
 		{
 			"description":"Test Struct 3 description",
-			"id": "Test Struct 3",
+			"id": "TestStruct3",
+			"title": "Test Struct 3",
 			"properties": {
 				"BaseFieldB": {
 					"description": "BaseFieldB comment",
@@ -208,7 +286,8 @@ func Test_OpenapiGenerator_Struct3(t *testing.T) {
 		},
 		{
 			"description":"Test Underlying Struct description",
-			"id": "Test Underlying Struct",
+			"id": "TestUnderlyingStruct",
+			"title": "Test Underlying Struct",
 			"properties": {
 				"UnderlyingFieldB": {
 					"description": "UnderlyingFieldB comment",
@@ -239,7 +318,8 @@ func Test_OpenapiGenerator_Struct4(t *testing.T) {
 	assert.NoError(t, err)
 	assert.JSONEq(t, `{
 		"description":"Test Struct 4 description",
-		"id": "Test Struct 4",
+		"id": "TestStruct4",
+		"title": "Test Struct 4",
 		"type":"object",
 		"properties": {
 			"otherFieldA": {
@@ -284,32 +364,10 @@ func Test_OpenapiGenerator_Method(t *testing.T) {
 	bytes, err := json.Marshal(specs)
 	assert.NoError(t, err)
 	assert.JSONEq(t, `[
-		{
-			"id": "HTTP Handler",
-			"type": "object"
-		},
-		{
-			"description": "MyAsset description",
-			"id": "MyAsset",
-			"properties": {
-				"other_structs": {
-					"items": {
-						"$ref": "#/components/schemas/TestOtherStruct5"
-					},
-					"type": "array"
-				},
-				"structs": {
-					"items": {
-						"$ref": "#/components/schemas/TestStruct4"
-					},
-					"type": "array"
-				}
-			},
-			"type": "object"
-		},
 		{
 			"description": "Test Other Struct 5 description",
-			"id": "Test Other Struct 5",
+			"id": "TestOtherStruct5",
+			"title": "Test Other Struct 5",
 			"properties": {
 				"BaseFieldB": {
 					"description": "BaseFieldB comment",
@@ -352,7 +410,8 @@ func Test_OpenapiGenerator_Method(t *testing.T) {
 		},
 		{
 			"description": "Test OtherUnderlying description",
-			"id": "Test OtherUnderlying Struct",
+			"id": "TestOtherUnderlyingStruct",
+			"title": "Test OtherUnderlying Struct",
 			"properties": {
 				"UnderlyingFieldB": {
 					"description": "UnderlyingFieldB comment",
@@ -371,7 +430,8 @@ func Test_OpenapiGenerator_Method(t *testing.T) {
 		},
 		{
 			"description": "Test Struct 4 description",
-			"id": "Test Struct 4",
+			"id": "TestStruct4",
+			"title": "Test Struct 4",
 			"properties": {
 				"otherFieldA": {
 					"description": "FieldA comment",
@@ -403,11 +463,346 @@ func Test_OpenapiGenerator_Method(t *testing.T) {
 				}
 			},
 			"type": "object"
+		},
+		{
+			"id": "httpHandler",
+			"title": "HTTP Handler",
+			"type": "object"
+		},
+		{
+			"description": "MyAsset description",
+			"id": "httpHandlerResp",
+			"title": "MyAsset",
+			"properties": {
+				"other_structs": {
+					"items": {
+						"$ref": "#/components/schemas/TestOtherStruct5"
+					},
+					"type": "array"
+				},
+				"structs": {
+					"items": {
+						"$ref": "#/components/schemas/TestStruct4"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
 		}
 	]
 `, string(bytes))
 }
 
+func Test_OpenapiGenerator_Interface(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile("TestPolymorphicStruct"), "json")
+	specs, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
+	assert.NoError(t, err)
+	assert.Len(t, specs, 4)
+
+	bytes, err := json.Marshal(specs[1])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"description": "Test Polymorphic Struct description",
+		"id": "TestPolymorphicStruct",
+		"title": "Test Polymorphic Struct",
+		"type": "object",
+		"properties": {
+			"FieldA": {
+				"description": "FieldA comment",
+				"nullable": true,
+				"oneOf": [
+					{"$ref": "#/components/schemas/TestCircle"},
+					{"$ref": "#/components/schemas/TestSquare"}
+				],
+				"discriminator": {
+					"propertyName": "kind",
+					"mapping": {
+						"circle": "#/components/schemas/TestCircle",
+						"square": "#/components/schemas/TestSquare"
+					}
+				}
+			},
+			"FieldB": {
+				"description": "FieldB comment",
+				"type": "array",
+				"items": {
+					"nullable": true,
+					"oneOf": [
+						{"$ref": "#/components/schemas/TestCircle"},
+						{"$ref": "#/components/schemas/TestSquare"}
+					],
+					"discriminator": {
+						"propertyName": "kind",
+						"mapping": {
+							"circle": "#/components/schemas/TestCircle",
+							"square": "#/components/schemas/TestSquare"
+						}
+					}
+				}
+			},
+			"FieldC": {
+				"description": "FieldC comment",
+				"nullable": true,
+				"anyOf": [
+					{"$ref": "#/components/schemas/TestCircle"},
+					{"$ref": "#/components/schemas/TestUnderlyingStruct"}
+				]
+			}
+		}
+	}`, string(bytes))
+	assert.Equal(t, []string{"TestCircle", "TestPolymorphicStruct", "TestSquare", "TestUnderlyingStruct"}, schemaIDs(specs))
+	bytes, err = json.Marshal(specs[0].Properties["kind"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"description": "Kind comment", "type": "string", "enum": ["circle"]}`, string(bytes))
+	assert.Empty(t, missingDescriptions(specs))
+}
+
+func Test_OpenapiGenerator_Interface_DuplicateDiscriminator(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shapes\n\ngo 1.19\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "shapes.go"), []byte(`package shapes
+
+type Shape interface{ Area() float64 }
+
+type Circle struct {
+	Kind string `+"`json:\"kind\" openapi:\"const=round\"`"+`
+}
+
+func (Circle) Area() float64 { return 0 }
+
+type Ellipse struct {
+	Kind string `+"`json:\"kind\" openapi:\"const=round\"`"+`
+}
+
+func (Ellipse) Area() float64 { return 0 }
+
+type Drawing struct {
+	Shape Shape `+"`json:\"shape\"`"+`
+}
+`), 0o644))
+
+	var logs bytes.Buffer
+	generator := NewOpenapiGenerator(regexp.MustCompile("^Drawing$"), "json", WithDir(dir), WithLogger(log.New(&logs, "", 0)))
+	specs, err := generator.DocumentStruct("./...")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Circle", "Drawing", "Ellipse"}, schemaIDs(specs))
+
+	// the implementations are not mutually exclusive without discriminator
+	out, err := json.Marshal(specs[1].Properties["shape"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"nullable": true,
+		"anyOf": [
+			{"$ref": "#/components/schemas/Circle"},
+			{"$ref": "#/components/schemas/Ellipse"}
+		]
+	}`, string(out))
+	assert.Contains(t, logs.String(), `Discriminator value "round" of Ellipse is already used by Circle`)
+}
+
+func Test_OpenapiGenerator_SchemaVariants(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile("TestItem"), "json", WithSchemaVariants(CreateVariant, UpdateVariant, PatchVariant))
+	specs, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TestItem", "TestItemCreate", "TestItemPatch", "TestItemUpdate",
+		"TestUnderlyingStruct", "TestUnderlyingStructCreate", "TestUnderlyingStructPatch", "TestUnderlyingStructUpdate"}, schemaIDs(specs))
+
+	bytes, err := json.Marshal(specs[0:1])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{
+			"description": "Test Item description",
+			"id": "TestItem",
+			"title": "Test Item",
+			"type": "object",
+			"required": ["id", "name"],
+			"properties": {
+				"id": {
+					"description": "ID comment",
+					"readOnly": true,
+					"type": "string"
+				},
+				"name": {
+					"description": "Name comment",
+					"type": "string"
+				},
+				"owner": {
+					"description": "Owner comment",
+					"allOf": [
+						{"$ref": "#/components/schemas/TestUnderlyingStruct"}
+					],
+					"readOnly": true
+				},
+				"secret": {
+					"description": "Secret comment",
+					"type": "string",
+					"writeOnly": true
+				}
+			}
+		}
+	]`, string(bytes))
+
+	bytes, err = json.Marshal(specs[1:3])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{
+			"description": "Test Item description",
+			"id": "TestItemCreate",
+			"title": "Test Item Create",
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {
+					"description": "Name comment",
+					"type": "string"
+				},
+				"secret": {
+					"description": "Secret comment",
+					"type": "string",
+					"writeOnly": true
+				}
+			}
+		},
+		{
+			"description": "Test Item description",
+			"id": "TestItemPatch",
+			"title": "Test Item Patch",
+			"type": "object",
+			"properties": {
+				"name": {
+					"description": "Name comment",
+					"type": "string"
+				},
+				"secret": {
+					"description": "Secret comment",
+					"type": "string",
+					"writeOnly": true
+				}
+			}
+		}
+	]`, string(bytes))
+
+	// the required readOnly id identifies the replaced resource
+	bytes, err = json.Marshal(specs[3])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"description": "Test Item description",
+		"id": "TestItemUpdate",
+		"title": "Test Item Update",
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {
+			"id": {
+				"description": "ID comment",
+				"type": "string"
+			},
+			"name": {
+				"description": "Name comment",
+				"type": "string"
+			},
+			"secret": {
+				"description": "Secret comment",
+				"type": "string",
+				"writeOnly": true
+			}
+		}
+	}`, string(bytes))
+	assert.Empty(t, missingDescriptions(specs))
+}
+
+func Test_OpenapiGenerator_TypeMapping(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile("TestTypeMappingStruct"), "json",
+		WithTypeMapping("github.com/mrahbar/gostruct2openapi/doc/testdata.TestMoney", TypeMapping{Type: "string", Pattern: `^\d+ [A-Z]{3}$`, Example: "10 EUR"}))
+	specs, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
+	assert.NoError(t, err)
+	assert.Len(t, specs, 1)
+
+	bytes, err := specs[0].MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"description": "Test Type Mapping Struct description",
+		"id": "TestTypeMappingStruct",
+		"title": "Test Type Mapping Struct",
+		"type": "object",
+		"properties": {
+			"FieldA": {
+				"description": "FieldA comment",
+				"type": "integer",
+				"format": "int64",
+				"example": 1000000000
+			},
+			"FieldB": {
+				"description": "FieldB comment",
+				"type": "string",
+				"example": "192.0.2.1"
+			},
+			"FieldC": {
+				"description": "FieldC comment",
+				"type": "integer"
+			},
+			"FieldD": {
+				"description": "FieldD comment",
+				"type": "array",
+				"items": {
+					"type": "string",
+					"example": "192.0.2.1"
+				}
+			},
+			"FieldE": {
+				"description": "FieldE comment",
+				"type": "object",
+				"additionalProperties": {
+					"type": "string",
+					"format": "RFC3339"
+				}
+			},
+			"FieldF": {
+				"description": "FieldF comment",
+				"type": "string",
+				"pattern": "^\\d+ [A-Z]{3}$",
+				"example": "10 EUR"
+			},
+			"FieldG": {
+				"description": "FieldG comment",
+				"type": "string",
+				"format": "byte"
+			},
+			"FieldH": {
+				"description": "FieldH comment",
+				"type": "integer",
+				"format": "int64"
+			}
+		}
+	}`, string(bytes))
+	assert.Empty(t, missingDescriptions(specs))
+}
+
+func Test_OpenapiGenerator_Workers(t *testing.T) {
+	generate := func(workers int) []byte {
+		generator := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", WithWorkers(workers), WithLogger(log.New(io.Discard, "", 0)))
+		specs, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata", "github.com/mrahbar/gostruct2openapi/testdata")
+		assert.NoError(t, err)
+		out, err := json.Marshal(specs)
+		assert.NoError(t, err)
+		return out
+	}
+
+	sequential := generate(1)
+	assert.NotEmpty(t, sequential)
+	for _, workers := range []int{0, 2, 8} {
+		assert.Equal(t, string(sequential), string(generate(workers)), "workers=%d", workers)
+	}
+}
+
+func Benchmark_OpenapiGenerator_DocumentStruct(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		generator := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", WithLogger(log.New(io.Discard, "", 0)))
+		if _, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func missingDescriptions(specs []spec.Schema) map[string][]string {
 	missing := make(map[string][]string)
 
@@ -422,3 +817,11 @@ func missingDescriptions(specs []spec.Schema) map[string][]string {
 
 	return missing
 }
+
+func schemaIDs(specs []spec.Schema) (ids []string) {
+	for _, schema := range specs {
+		ids = append(ids, schema.ID)
+	}
+
+	return
+}
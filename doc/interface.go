@@ -0,0 +1,192 @@
+package doc
+
+import (
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc/internal"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// processInterface resolves the implementations of an interface typed field and returns a spec field
+// referencing them. If the implementations share a constant field with distinct values they are mutually
+// exclusive and referenced by oneOf with a discriminator, otherwise by anyOf. Since a nil interface is
+// encoded as null the field or the items of a slice are nullable. Nil is returned if no implementation is known.
+func (o *openapiGenerator) processInterface(typ types.Type, iface *types.Interface, isArray bool) (*internal.SpecField, SpecRegistry) {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return nil, nil
+	}
+
+	impls := o.implementationsOf(named, iface)
+	if len(impls) == 0 {
+		return nil, nil
+	}
+
+	specs := make(SpecRegistry)
+	var refs []string
+	for _, impl := range impls {
+		name := impl.Obj().Name()
+		refs = append(refs, name)
+		specs.Extend(o.processTarget(internal.NewTargetStruct(name, impl, impl.Underlying().(*types.Struct))))
+	}
+
+	var sf *internal.SpecField
+	if isArray {
+		sf = internal.NewArraySpecField(internal.StructType)
+		sf.SetOneOf(refs...)
+	} else {
+		sf = internal.NewOneOfSpecField(refs...)
+	}
+	sf.SetNullable()
+	sf.SetDiscriminator(o.discriminatorOf(impls))
+
+	return sf, specs
+}
+
+// implementationsOf returns the struct types implementing the given interface sorted by name.
+// Types listed by the @implementations annotation take precedence over the types found in the loaded packages.
+func (o *openapiGenerator) implementationsOf(named *types.Named, iface *types.Interface) []*types.Named {
+	o.loadComments(named)
+	id := fmt.Sprintf("%s.%s", named.Obj().Pkg().Path(), named.Obj().Name())
+	metadata := o.metadataParser.ParseStructDesc(o.commentRegistry.Lookup(id))
+
+	var impls []*types.Named
+	if annotated := metadata.Lookup(internal.ImplementationsAttr, ""); len(annotated) > 0 {
+		for _, name := range strings.FieldsFunc(annotated, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+			if impl := o.lookupNamedStruct(named.Obj().Pkg(), name); impl != nil {
+				impls = append(impls, impl)
+			} else {
+				o.logger.Printf("Implementation %q of interface %s is not a known struct\n", name, id)
+			}
+		}
+	} else if !iface.Empty() {
+		// every type implements the empty interface, therefore only non-empty interfaces are resolved
+		seen := make(map[*types.Named]struct{})
+		o.scannedPackages = true
+		for _, pkg := range o.packages {
+			scope := pkg.Types.Scope()
+			for _, name := range scope.Names() {
+				obj, ok := scope.Lookup(name).(*types.TypeName)
+				if !ok || obj.IsAlias() {
+					continue
+				}
+				impl, ok := obj.Type().(*types.Named)
+				if !ok {
+					continue
+				}
+				if _, isStruct := impl.Underlying().(*types.Struct); !isStruct {
+					continue
+				}
+				if _, exists := seen[impl]; exists {
+					continue
+				}
+				if types.Implements(impl, iface) || types.Implements(types.NewPointer(impl), iface) {
+					seen[impl] = struct{}{}
+					impls = append(impls, impl)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(impls, func(i, j int) bool {
+		return impls[i].Obj().Name() < impls[j].Obj().Name()
+	})
+	return impls
+}
+
+// lookupNamedStruct resolves a struct type by its name. Unqualified names are looked up in the given package,
+// qualified names (pkg.Type) in the imports of the given package and in the loaded packages.
+func (o *openapiGenerator) lookupNamedStruct(pkg *types.Package, name string) *types.Named {
+	scopes := []*types.Scope{pkg.Scope()}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		pkgName := name[:i]
+		name = name[i+1:]
+		scopes = nil
+		for _, imp := range pkg.Imports() {
+			if imp.Name() == pkgName || imp.Path() == pkgName {
+				scopes = append(scopes, imp.Scope())
+			}
+		}
+		o.scannedPackages = true
+		for _, p := range o.packages {
+			if p.Types.Name() == pkgName || p.Types.Path() == pkgName {
+				scopes = append(scopes, p.Types.Scope())
+			}
+		}
+	}
+
+	for _, scope := range scopes {
+		if obj, ok := scope.Lookup(name).(*types.TypeName); ok {
+			if impl, ok := obj.Type().(*types.Named); ok {
+				if _, isStruct := impl.Underlying().(*types.Struct); isStruct {
+					return impl
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// discriminatorOf returns the discriminator shared by all implementations or nil if at least one implementation
+// has no constant field, e.g. `openapi:"const=circle"`, the constant fields differ in their property names or
+// two implementations share a value
+func (o *openapiGenerator) discriminatorOf(impls []*types.Named) *internal.Discriminator {
+	discriminator := &internal.Discriminator{Mapping: make(map[string]string)}
+
+	for _, impl := range impls {
+		propertyName, value := o.discriminatorField(impl.Obj().Name(), impl.Underlying().(*types.Struct))
+		if len(value) == 0 {
+			return nil
+		}
+		if len(discriminator.PropertyName) == 0 {
+			discriminator.PropertyName = propertyName
+		} else if discriminator.PropertyName != propertyName {
+			o.logger.Printf("Discriminator property %q of %s differs from %q\n", propertyName, impl.Obj().Name(), discriminator.PropertyName)
+			return nil
+		}
+		if other, exists := discriminator.Mapping[value]; exists {
+			o.logger.Printf("Discriminator value %q of %s is already used by %s\n", value, impl.Obj().Name(), other)
+			return nil
+		}
+		discriminator.Mapping[value] = impl.Obj().Name()
+	}
+
+	return discriminator
+}
+
+// discriminatorField returns the property name and the value of the string field tagged as constant by the
+// openapi struct tag. Fields of embedded structs are considered as well.
+func (o *openapiGenerator) discriminatorField(structName string, _struct *types.Struct) (string, string) {
+	for i := 0; i < _struct.NumFields(); i++ {
+		field := _struct.Field(i)
+		if field.Embedded() {
+			if embedded, ok := field.Type().Underlying().(*types.Struct); ok {
+				if propertyName, value := o.discriminatorField(field.Name(), embedded); len(value) > 0 {
+					return propertyName, value
+				}
+			}
+			continue
+		}
+
+		tf := internal.NewTargetField(field.Pkg().Path(), structName, _struct.Tag(i), field.Name())
+		markers := internal.ParseFieldMarkers(nil, tf.TagOptions(internal.OpenapiTag))
+		basic, isBasic := field.Type().Underlying().(*types.Basic)
+		if field.Exported() && isBasic && basic.Info()&types.IsString != 0 && len(markers.Const) > 0 {
+			return tf.CanonicalFieldName(o.structTag), markers.Const
+		}
+	}
+
+	return "", ""
+}
+
+// loadComments makes sure the comments of the package declaring the given type are registered
+func (o *openapiGenerator) loadComments(named *types.Named) {
+	if named.Obj().Pkg() == nil {
+		return
+	}
+	if pkg, err := o.index.syntax(named.Obj().Pkg().Path()); err == nil {
+		o.commentRegistry.Load(pkg)
+	}
+}
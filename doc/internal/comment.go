@@ -2,35 +2,54 @@ package internal
 
 import (
 	"fmt"
-	"github.com/mrahbar/gostruct2openapi/doc/internal/util"
 	"go/ast"
 	"go/doc"
 	"golang.org/x/tools/go/packages"
 	"strings"
+	"sync"
 )
 
+// CommentRegistry holds the comments of structs and struct fields. It is safe for concurrent use.
 type CommentRegistry struct {
-	loadedPackages []string
+	mu sync.RWMutex
+	// loadedPackages holds a channel per package ID which is closed once the comments of the package are registered
+	loadedPackages map[string]chan struct{}
 	registry       map[string]string
 }
 
 func NewCommentRegistry() *CommentRegistry {
-	return &CommentRegistry{registry: make(map[string]string)}
+	return &CommentRegistry{loadedPackages: make(map[string]chan struct{}), registry: make(map[string]string)}
 }
 
 // Load loads struct as well as struct field comments and builds comment registry for given packages.
+// Each package is loaded once, concurrent calls for a package being loaded wait until it is registered.
 func (c *CommentRegistry) Load(pkgs ...*packages.Package) {
 	for _, pkg := range pkgs {
-		if util.Contains(c.loadedPackages, pkg.ID) {
+		c.mu.Lock()
+		loaded, exists := c.loadedPackages[pkg.ID]
+		if exists {
+			c.mu.Unlock()
+			<-loaded
 			continue
 		}
-		c.loadedPackages = append(c.loadedPackages, pkg.ID)
-		c.loadStructComments(pkg)
-		c.loadStructFieldComments(pkg)
+		loaded = make(chan struct{})
+		c.loadedPackages[pkg.ID] = loaded
+		c.mu.Unlock()
+
+		comments := make(map[string]string)
+		loadStructComments(pkg, comments)
+		loadStructFieldComments(pkg, comments)
+
+		c.mu.Lock()
+		for key, value := range comments {
+			c.registry[key] = value
+		}
+		c.mu.Unlock()
+		close(loaded)
 	}
 }
 
-func (c *CommentRegistry) loadStructComments(pkg *packages.Package) {
+func loadStructComments(pkg *packages.Package, comments map[string]string) {
 	//transform package.Package to ast.Package
 	//note that only the necessary fields are set used by go/doc
 	a := &ast.Package{Name: pkg.ID, Files: make(map[string]*ast.File)}
@@ -41,12 +60,12 @@ func (c *CommentRegistry) loadStructComments(pkg *packages.Package) {
 	p := doc.New(a, ".", doc.AllDecls)
 	for _, t := range p.Types {
 		if len(t.Doc) > 0 {
-			c.register(fmt.Sprintf("%s.%s", pkg.ID, t.Name), t.Doc)
+			register(comments, fmt.Sprintf("%s.%s", pkg.ID, t.Name), t.Doc)
 		}
 	}
 }
 
-func (c *CommentRegistry) loadStructFieldComments(pkg *packages.Package) {
+func loadStructFieldComments(pkg *packages.Package, comments map[string]string) {
 	for _, syntax := range pkg.Syntax {
 		for structName, object := range syntax.Scope.Objects {
 			switch t := object.Decl.(type) {
@@ -68,7 +87,7 @@ func (c *CommentRegistry) loadStructFieldComments(pkg *packages.Package) {
 								f, ok := name.Obj.Decl.(*ast.Field)
 								if ok && len(f.Doc.Text()) > 0 {
 									tf := &TargetField{fieldName: name.Name, structName: structName, packageID: pkg.ID}
-									c.register(tf.ID(), f.Doc.Text())
+									register(comments, tf.ID(), f.Doc.Text())
 								}
 							}
 						}
@@ -79,10 +98,12 @@ func (c *CommentRegistry) loadStructFieldComments(pkg *packages.Package) {
 	}
 }
 
-func (c *CommentRegistry) register(key, value string) {
-	c.registry[strings.ToLower(key)] = value
+func register(comments map[string]string, key, value string) {
+	comments[strings.ToLower(key)] = value
 }
 
 func (c *CommentRegistry) Lookup(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.registry[strings.ToLower(key)]
 }
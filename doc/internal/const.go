@@ -19,10 +19,23 @@ const (
 )
 
 var StructFieldTypeMap = map[string]*SpecField{
-	"string":    NewSpecField(StringType),
-	"int":       NewSpecField(IntegerType),
-	"float32":   NewSpecField(NumberType),
-	"float64":   NewSpecField(NumberType),
-	"bool":      NewSpecField(BooleanType),
-	"time.Time": NewSpecFieldWithFormat(StringType, TimeFormat),
+	"string":  NewSpecField(StringType),
+	"int":     NewSpecField(IntegerType),
+	"int8":    NewSpecField(IntegerType),
+	"int16":   NewSpecField(IntegerType),
+	"int32":   NewSpecFieldWithFormat(IntegerType, "int32"),
+	"int64":   NewSpecFieldWithFormat(IntegerType, "int64"),
+	"rune":    NewSpecFieldWithFormat(IntegerType, "int32"),
+	"uint":    NewSpecField(IntegerType),
+	"uint8":   NewSpecField(IntegerType),
+	"byte":    NewSpecField(IntegerType),
+	"uint16":  NewSpecField(IntegerType),
+	"uint32":  NewSpecField(IntegerType),
+	"uint64":  NewSpecField(IntegerType),
+	"float32": NewSpecField(NumberType),
+	"float64": NewSpecField(NumberType),
+	"bool":    NewSpecField(BooleanType),
+	// []byte is encoded as base64 string by encoding/json
+	"[]byte":  NewSpecFieldWithFormat(StringType, "byte"),
+	"[]uint8": NewSpecFieldWithFormat(StringType, "byte"),
 }
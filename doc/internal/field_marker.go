@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"github.com/go-openapi/spec"
+	"strings"
+)
+
+const (
+	// OpenapiTag is the struct tag holding field markers, e.g. `openapi:"readOnly,required"` or `openapi:"const=circle"`
+	OpenapiTag = "openapi"
+
+	ReadOnlyAttr  = "@readOnly"
+	WriteOnlyAttr = "@writeOnly"
+	RequiredAttr  = "@required"
+
+	readOnlyMarker  = "readOnly"
+	writeOnlyMarker = "writeOnly"
+	requiredMarker  = "required"
+	constMarker     = "const="
+)
+
+// FieldMarkers holds the markers of a struct field given by comment directives or the openapi struct tag
+type FieldMarkers struct {
+	ReadOnly  bool
+	WriteOnly bool
+	Required  bool
+	// Const is the only value of the field, e.g. the discriminator value of an interface implementation
+	Const string
+}
+
+// ParseFieldMarkers returns the markers found in the field comment metadata and the openapi struct tag options
+func ParseFieldMarkers(metadata StructMetadata, tagOptions []string) FieldMarkers {
+	markers := FieldMarkers{
+		ReadOnly:  metadata.Has(ReadOnlyAttr),
+		WriteOnly: metadata.Has(WriteOnlyAttr),
+		Required:  metadata.Has(RequiredAttr),
+	}
+	for _, option := range tagOptions {
+		switch option {
+		case readOnlyMarker:
+			markers.ReadOnly = true
+		case writeOnlyMarker:
+			markers.WriteOnly = true
+		case requiredMarker:
+			markers.Required = true
+		default:
+			if strings.HasPrefix(option, constMarker) {
+				markers.Const = strings.TrimPrefix(option, constMarker)
+			}
+		}
+	}
+
+	return markers
+}
+
+// Apply sets readOnly, writeOnly and the constant as single enum value on the given schema. Since siblings of $ref
+// are not allowed a reference is wrapped into allOf to carry the markers and the description.
+func (f FieldMarkers) Apply(schema *spec.Schema, description string) {
+	if !f.ReadOnly && !f.WriteOnly && len(f.Const) == 0 {
+		return
+	}
+
+	wrapRef(schema, description)
+	if len(f.Const) > 0 {
+		schema.Enum = []interface{}{f.Const}
+	}
+	schema.ReadOnly = f.ReadOnly
+	if f.WriteOnly {
+		if schema.ExtraProps == nil {
+			schema.ExtraProps = make(map[string]interface{})
+		}
+		schema.ExtraProps["writeOnly"] = true
+	}
+}
+
+// ApplyExample sets the example on the given schema, a reference is wrapped like by FieldMarkers.Apply
+func ApplyExample(schema *spec.Schema, description string, example interface{}) {
+	wrapRef(schema, description)
+	schema.Example = example
+}
+
+// wrapRef wraps a reference into allOf, since siblings of $ref are ignored
+func wrapRef(schema *spec.Schema, description string) {
+	if schema.Ref.String() != "" {
+		*schema = spec.Schema{SchemaProps: spec.SchemaProps{
+			Description: description,
+			AllOf:       []spec.Schema{{SchemaProps: spec.SchemaProps{Ref: schema.Ref}}},
+		}}
+	}
+}
@@ -0,0 +1,125 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ComponentRefPrefix is the prefix of local references to component schemas
+const ComponentRefPrefix = "#/components/schemas/"
+
+// AsObject returns the value if it is a decoded JSON object, otherwise nil
+func AsObject(value interface{}) map[string]interface{} {
+	object, _ := value.(map[string]interface{})
+	return object
+}
+
+// AsArray returns the value if it is a decoded JSON array, otherwise nil
+func AsArray(value interface{}) []interface{} {
+	array, _ := value.([]interface{})
+	return array
+}
+
+// AsStrings returns the strings of a decoded JSON array
+func AsStrings(value interface{}) (res []string) {
+	for _, item := range AsArray(value) {
+		if s, ok := item.(string); ok {
+			res = append(res, s)
+		}
+	}
+
+	return
+}
+
+// JSONString returns the compact JSON encoding of the value
+func JSONString(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(raw)
+}
+
+// Number returns the numeric value of a schema keyword, which is a json.Number if decoded with UseNumber
+func Number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// Type returns the type of the schema, the first type other than null of OpenAPI 3.1 type arrays
+func Type(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, entry := range t {
+			if s, ok := entry.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+
+	return ""
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// EscapePointer escapes a reference token of a JSON pointer
+func EscapePointer(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+// UnescapePointer unescapes a reference token of a JSON pointer
+func UnescapePointer(token string) string {
+	return pointerUnescaper.Replace(token)
+}
+
+// RefName returns the escaped last token of a reference, i.e. the component name of a local reference
+func RefName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// DiscriminatorValue returns the value of the discriminator property selecting the referenced schema.
+// Without mapping the value is the name of the component.
+func DiscriminatorValue(discriminator map[string]interface{}, ref string) string {
+	mapping := AsObject(discriminator["mapping"])
+	values := make([]string, 0, len(mapping))
+	for value := range mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		if mapping[value] == ref {
+			return value
+		}
+	}
+
+	return UnescapePointer(RefName(ref))
+}
+
+// DiscriminatorRef returns the reference of the schema of oneOf selected by the value of the discriminator property.
+// Without mapping the value is the name of the component.
+func DiscriminatorRef(discriminator map[string]interface{}, oneOf []interface{}, value string) (string, bool) {
+	if ref, mapped := AsObject(discriminator["mapping"])[value].(string); mapped {
+		return ref, true
+	}
+	for _, subschema := range oneOf {
+		if ref, _ := AsObject(subschema)["$ref"].(string); ref == ComponentRefPrefix+EscapePointer(value) {
+			return ref, true
+		}
+	}
+
+	return "", false
+}
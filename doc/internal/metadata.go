@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -15,6 +16,11 @@ func (s StructMetadata) append(key string, value string) {
 	}
 }
 
+func (s StructMetadata) Has(key string) bool {
+	_, exists := s[key]
+	return exists
+}
+
 func (s StructMetadata) Lookup(key string, _default string) string {
 	if v, exists := s[key]; exists {
 		return v
@@ -27,8 +33,23 @@ const (
 	metadataToken   = "@"
 	DescriptionAttr = "@description"
 	TitleAttr       = "@title"
+	// ImplementationsAttr lists the types implementing an interface, separated by space or comma
+	ImplementationsAttr = "@implementations"
+	// ExampleAttr is the example of a struct or field, either JSON or a plain string, see ParseExample
+	ExampleAttr = "@example"
 )
 
+// ParseExample returns the value of an @example directive, which is parsed as JSON, e.g. 42 or {"name": "x"},
+// and otherwise used as string
+func ParseExample(value string) interface{} {
+	var example interface{}
+	if err := json.Unmarshal([]byte(value), &example); err != nil {
+		return value
+	}
+
+	return example
+}
+
 type MetadataParser struct {
 }
 
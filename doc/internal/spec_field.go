@@ -2,9 +2,35 @@ package internal
 
 import "github.com/go-openapi/spec"
 
+const schemaRefPrefix = "#/components/schemas/"
+
+// Discriminator describes the OpenAPI discriminator object of a polymorphic field
+type Discriminator struct {
+	PropertyName string
+	Mapping      map[string]string
+}
+
+func (d *Discriminator) toExtraProp() map[string]interface{} {
+	discriminator := map[string]interface{}{"propertyName": d.PropertyName}
+	if len(d.Mapping) > 0 {
+		mapping := make(map[string]string)
+		for value, ref := range d.Mapping {
+			mapping[value] = schemaRefPrefix + ref
+		}
+		discriminator["mapping"] = mapping
+	}
+	return discriminator
+}
+
 type SpecField struct {
 	baseType, itemsType SpecType
 	format, ref         string
+	pattern             string
+	example             interface{}
+	items               *SpecField
+	oneOf               []string
+	discriminator       *Discriminator
+	nullable            bool
 }
 
 func NewSpecFieldWithFormat(baseType SpecType, format string) *SpecField {
@@ -19,10 +45,19 @@ func NewArraySpecField(itemsType SpecType) *SpecField {
 	return &SpecField{baseType: ArrayType, itemsType: itemsType}
 }
 
+// NewArrayOfSpecField returns an array spec field whose items are described by the given spec field
+func NewArrayOfSpecField(items *SpecField) *SpecField {
+	return &SpecField{baseType: ArrayType, itemsType: items.baseType, items: items}
+}
+
 func NewStructSpecField(ref string) *SpecField {
 	return &SpecField{baseType: StructType, ref: ref}
 }
 
+func NewOneOfSpecField(refs ...string) *SpecField {
+	return &SpecField{baseType: StructType, oneOf: refs}
+}
+
 func (s *SpecField) BaseType() SpecType {
 	return s.baseType
 }
@@ -35,37 +70,87 @@ func (s *SpecField) SetFormat(format string) {
 	s.format = format
 }
 
+func (s *SpecField) SetPattern(pattern string) {
+	s.pattern = pattern
+}
+
+func (s *SpecField) SetExample(example interface{}) {
+	s.example = example
+}
+
 func (s *SpecField) SetRef(ref string) {
 	s.ref = ref
 }
 
+func (s *SpecField) SetOneOf(refs ...string) {
+	s.oneOf = refs
+}
+
+func (s *SpecField) SetDiscriminator(discriminator *Discriminator) {
+	s.discriminator = discriminator
+}
+
+// SetNullable allows null, e.g. for a nil interface
+func (s *SpecField) SetNullable() {
+	s.nullable = true
+}
+
 func (s *SpecField) IsValid() bool {
-	return s.format != "" || s.ref != "" || s.baseType != ""
+	return s.format != "" || s.ref != "" || s.baseType != "" || len(s.oneOf) > 0
 }
 
-func (s *SpecField) ToSchemaProp(description string) spec.SchemaProps {
-	schemaProps := spec.SchemaProps{
+func (s *SpecField) ToSchema(description string) spec.Schema {
+	schema := spec.Schema{SchemaProps: spec.SchemaProps{
 		Format:      s.format,
 		Description: description,
-	}
+	}}
 
 	if s.baseType == ArrayType {
-		var props spec.SchemaProps
-		if s.ref != "" {
-			props = spec.SchemaProps{Ref: spec.MustCreateRef("#/components/schemas/" + s.ref)}
+		var items spec.Schema
+		if s.items != nil {
+			items = s.items.ToSchema("")
+		} else if s.ref != "" {
+			items.Ref = spec.MustCreateRef(schemaRefPrefix + s.ref)
+		} else if len(s.oneOf) > 0 {
+			s.setOneOf(&items)
+			items.Nullable = s.nullable
 		} else if s.itemsType != "" {
-			props = spec.SchemaProps{Type: []string{s.itemsType.String()}}
+			items.Type = []string{s.itemsType.String()}
 		}
-		schemaProps.Type = []string{s.baseType.String()}
-		schemaProps.Items = &spec.SchemaOrArray{Schema: &spec.Schema{SchemaProps: props}}
+		schema.Type = []string{s.baseType.String()}
+		schema.Items = &spec.SchemaOrArray{Schema: &items}
 	} else {
 		if s.ref != "" {
-			schemaProps.Ref = spec.MustCreateRef("#/components/schemas/" + s.ref)
-			schemaProps.Description = "" //Property 'description' is not allowed for $ref
+			schema.Ref = spec.MustCreateRef(schemaRefPrefix + s.ref)
+			schema.Description = "" //Property 'description' is not allowed for $ref
+		} else if len(s.oneOf) > 0 {
+			s.setOneOf(&schema)
+			schema.Nullable = s.nullable
 		} else {
-			schemaProps.Type = []string{s.baseType.String()}
+			schema.Type = []string{s.baseType.String()}
+			schema.Pattern = s.pattern
+			schema.Example = s.example
 		}
 	}
 
-	return schemaProps
+	return schema
+}
+
+// setOneOf references the alternatives by oneOf if the discriminator makes them mutually exclusive, otherwise
+// a value may match several alternatives and they are referenced by anyOf
+func (s *SpecField) setOneOf(schema *spec.Schema) {
+	for _, ref := range s.oneOf {
+		alternative := spec.Schema{SchemaProps: spec.SchemaProps{Ref: spec.MustCreateRef(schemaRefPrefix + ref)}}
+		if s.discriminator != nil {
+			schema.OneOf = append(schema.OneOf, alternative)
+		} else {
+			schema.AnyOf = append(schema.AnyOf, alternative)
+		}
+	}
+	if s.discriminator != nil {
+		if schema.ExtraProps == nil {
+			schema.ExtraProps = make(map[string]interface{})
+		}
+		schema.ExtraProps["discriminator"] = s.discriminator.toExtraProp()
+	}
 }
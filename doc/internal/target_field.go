@@ -75,16 +75,42 @@ func (t *TargetField) ID() string {
 }
 
 func (t *TargetField) CanonicalFieldName(structTag string) string {
-	var fieldName = t.fieldName
+	if name := t.TagName(structTag); len(name) > 0 {
+		return name
+	}
+
+	return t.fieldName
+}
+
+// TagOptions returns the name and the options of the given struct tag key
+func (t *TargetField) TagOptions(key string) []string {
+	var options []string
+	if len(t.fieldTag) > 0 {
+		if tags, err := structtag.Parse(t.fieldTag); err == nil {
+			if tag, err := tags.Get(key); err == nil {
+				if len(tag.Name) > 0 {
+					options = append(options, tag.Name)
+				}
+				options = append(options, tag.Options...)
+			}
+		}
+	}
+
+	return options
+}
+
+// TagName returns the name of the given struct tag key or an empty string if the field has no such tag
+func (t *TargetField) TagName(key string) string {
+	var name string
 	if len(t.fieldTag) > 0 {
 		if tags, err := structtag.Parse(t.fieldTag); err == nil {
 			for _, tp := range tags.Tags() {
-				if tp.Key == structTag {
-					fieldName = tp.Name
+				if tp.Key == key {
+					name = tp.Name
 				}
 			}
 		}
 	}
 
-	return fieldName
+	return name
 }
@@ -0,0 +1,139 @@
+package doc
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strconv"
+	"strings"
+)
+
+// jsonPathMatch is a node selected by a JSONPath expression together with its position in the parent node
+type jsonPathMatch struct {
+	parent *yaml.Node
+	// index of the value node in the content of the parent, -1 for the root node
+	index int
+	node  *yaml.Node
+}
+
+// jsonPathSegment is a child selector of a JSONPath expression, wildcard selects all children
+type jsonPathSegment struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses the subset of JSONPath consisting of the root $, dot and bracket child selectors,
+// array indices and wildcards, e.g. $.components.schemas['My Schema'].required[0]
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath %q does not start with $", path)
+	}
+
+	var segments []jsonPathSegment
+	rest := path[1:]
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return nil, fmt.Errorf("JSONPath %q: descendant selector is not supported", path)
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if len(name) == 0 {
+				return nil, fmt.Errorf("JSONPath %q: empty name", path)
+			}
+			segments = append(segments, jsonPathSegment{name: name, wildcard: name == "*"})
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			end := closingBracket(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q: missing closing bracket", path)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				name := strings.ReplaceAll(selector[1:len(selector)-1], `\`+selector[:1], selector[:1])
+				segments = append(segments, jsonPathSegment{name: name})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath %q: selector [%s] is not supported", path, selector)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath %q: unexpected %q", path, rest[:1])
+		}
+	}
+
+	return segments, nil
+}
+
+// closingBracket returns the index of the bracket closing the selector at the start of s, quoted brackets are skipped
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == '\\':
+			i++
+		case quote != 0 && s[i] == quote:
+			quote = 0
+		case quote == 0 && (s[i] == '\'' || s[i] == '"'):
+			quote = s[i]
+		case quote == 0 && s[i] == ']':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// evalJSONPath returns all nodes of the document root selected by the JSONPath expression
+func evalJSONPath(root *yaml.Node, path string) ([]jsonPathMatch, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []jsonPathMatch{{index: -1, node: root}}
+	for _, segment := range segments {
+		var next []jsonPathMatch
+		for _, match := range matches {
+			node := match.node
+			switch node.Kind {
+			case yaml.MappingNode:
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if segment.wildcard || (!segment.isIndex && node.Content[i].Value == segment.name) {
+						next = append(next, jsonPathMatch{parent: node, index: i + 1, node: node.Content[i+1]})
+					}
+				}
+			case yaml.SequenceNode:
+				for i, item := range node.Content {
+					if segment.wildcard || (segment.isIndex && (segment.index == i || segment.index == i-len(node.Content))) {
+						next = append(next, jsonPathMatch{parent: node, index: i, node: item})
+					}
+				}
+			}
+		}
+		matches = next
+	}
+
+	return matches, nil
+}
+
+// jsonPathName returns the child selector of the given name, using bracket notation if required
+func jsonPathName(name string) string {
+	for _, r := range name {
+		if !(r == '_' || r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			return "['" + strings.ReplaceAll(name, "'", `\'`) + "']"
+		}
+	}
+
+	return "." + name
+}
@@ -0,0 +1,568 @@
+package doc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc/internal"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zeroEncodings are the JSON encodings of the zero values of registered types with a custom encoding
+var zeroEncodings = map[string]interface{}{
+	"time.Time":                             "0001-01-01T00:00:00Z",
+	"net/netip.Addr":                        "",
+	"net/netip.AddrPort":                    "",
+	"net/netip.Prefix":                      "",
+	"github.com/google/uuid.UUID":           "00000000-0000-0000-0000-000000000000",
+	"github.com/gofrs/uuid.UUID":            "00000000-0000-0000-0000-000000000000",
+	"github.com/shopspring/decimal.Decimal": "0",
+}
+
+// literalExamples evaluates the package level variables annotated with @example, e.g. "// @example Order" above
+// "var ExampleOrder = Order{...}", and returns their JSON values by the ID of the annotated struct. Variables which
+// cannot be evaluated are logged and skipped.
+func (o *openapiGenerator) literalExamples(pkgs []*packages.Package) map[string]interface{} {
+	l := &literalEvaluator{
+		structTag:    o.structTag,
+		typeRegistry: o.typeRegistry,
+		initializers: make(map[*types.Var]initializer),
+		visiting:     make(map[*types.Var]bool),
+	}
+	for _, pkg := range pkgs {
+		l.index(pkg)
+	}
+
+	examples := make(map[string]interface{})
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.VAR {
+					continue
+				}
+				for _, s := range gen.Specs {
+					valueSpec := s.(*ast.ValueSpec)
+					comment := valueSpec.Doc
+					if comment == nil && !gen.Lparen.IsValid() {
+						comment = gen.Doc
+					}
+					metadata := o.metadataParser.ParseStructDesc(comment.Text())
+					if !metadata.Has(internal.ExampleAttr) {
+						continue
+					}
+					for _, name := range valueSpec.Names {
+						variable, ok := pkg.TypesInfo.Defs[name].(*types.Var)
+						if !ok {
+							continue
+						}
+						id, example, err := l.annotated(pkg, variable, metadata[internal.ExampleAttr])
+						if err != nil {
+							o.logger.Printf("Skipping example %s: %v\n", variable.Name(), err)
+							continue
+						}
+						if _, exists := examples[id]; exists {
+							o.logger.Printf("Skipping example %s: %s already has an example\n", variable.Name(), id)
+							continue
+						}
+						examples[id] = example
+					}
+				}
+			}
+		}
+	}
+
+	return examples
+}
+
+// initializer is the expression a package level variable is initialized with
+type initializer struct {
+	pkg  *packages.Package
+	expr ast.Expr
+}
+
+// literalEvaluator converts constant expressions and composite literals to the values encoding/json would produce
+type literalEvaluator struct {
+	structTag    string
+	typeRegistry *TypeRegistry
+	initializers map[*types.Var]initializer
+	// visiting holds the variables being evaluated to detect initialization cycles
+	visiting map[*types.Var]bool
+}
+
+// index registers the initializers of the package level variables of the package
+func (l *literalEvaluator) index(pkg *packages.Package) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, s := range gen.Specs {
+				valueSpec := s.(*ast.ValueSpec)
+				if len(valueSpec.Values) != len(valueSpec.Names) {
+					continue
+				}
+				for i, name := range valueSpec.Names {
+					if variable, ok := pkg.TypesInfo.Defs[name].(*types.Var); ok {
+						l.initializers[variable] = initializer{pkg: pkg, expr: valueSpec.Values[i]}
+					}
+				}
+			}
+		}
+	}
+}
+
+// annotated evaluates the variable and returns the ID of the struct named by the @example directive, an empty name
+// denotes the type of the variable
+func (l *literalEvaluator) annotated(pkg *packages.Package, variable *types.Var, name string) (string, interface{}, error) {
+	typ := variable.Type()
+	if pointer, ok := typ.(*types.Pointer); ok {
+		typ = pointer.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return "", nil, fmt.Errorf("type %s is not a named struct", typ)
+	}
+	if len(name) > 0 {
+		object, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return "", nil, fmt.Errorf("type %s not found in package %s", name, pkg.PkgPath)
+		}
+		if !types.Identical(object.Type(), named) {
+			return "", nil, fmt.Errorf("type %s does not match @example %s", named.Obj().Name(), name)
+		}
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return "", nil, fmt.Errorf("type %s is not a named struct", named.Obj().Name())
+	}
+
+	value, err := l.variable(variable)
+	if err != nil {
+		return "", nil, err
+	}
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name(), value, nil
+}
+
+// variable evaluates the initializer of the package level variable
+func (l *literalEvaluator) variable(variable *types.Var) (interface{}, error) {
+	init, exists := l.initializers[variable]
+	if !exists {
+		return nil, fmt.Errorf("variable %s has no initializer in the loaded packages", variable.Name())
+	}
+	if l.visiting[variable] {
+		return nil, fmt.Errorf("variable %s refers to itself", variable.Name())
+	}
+	l.visiting[variable] = true
+	defer func() { l.visiting[variable] = false }()
+
+	return l.eval(init.pkg, init.expr)
+}
+
+func (l *literalEvaluator) eval(pkg *packages.Package, expr ast.Expr) (interface{}, error) {
+	tv := pkg.TypesInfo.Types[expr]
+	if tv.Value != nil {
+		if hasCustomEncoding(tv.Type) {
+			return nil, l.unsupported(pkg, expr, "custom JSON encoding of "+tv.Type.String())
+		}
+		return constantValue(pkg, expr, tv.Type, tv.Value)
+	}
+	if tv.IsNil() {
+		return nil, nil
+	}
+
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return l.eval(pkg, e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return l.eval(pkg, e.X)
+		}
+	case *ast.Ident:
+		if variable, ok := pkg.TypesInfo.Uses[e].(*types.Var); ok && variable.Parent() == variable.Pkg().Scope() {
+			return l.variable(variable)
+		}
+	case *ast.SelectorExpr:
+		if variable, ok := pkg.TypesInfo.Uses[e.Sel].(*types.Var); ok && variable.Parent() == variable.Pkg().Scope() {
+			return l.variable(variable)
+		}
+	case *ast.CompositeLit:
+		return l.composite(pkg, tv.Type, e)
+	case *ast.CallExpr:
+		return l.call(pkg, e)
+	}
+
+	return nil, l.unsupported(pkg, expr, "")
+}
+
+// composite evaluates a struct, slice, array or map literal
+func (l *literalEvaluator) composite(pkg *packages.Package, typ types.Type, lit *ast.CompositeLit) (interface{}, error) {
+	if pointer, ok := typ.Underlying().(*types.Pointer); ok {
+		// elided &T of elements of e.g. []*T{{...}}
+		typ = pointer.Elem()
+	}
+	if name := qualifiedName(typ); len(name) > 0 && len(lit.Elts) == 0 {
+		if zero, exists := zeroEncodings[name]; exists {
+			return zero, nil
+		}
+	}
+	if hasCustomEncoding(typ) {
+		return nil, l.unsupported(pkg, lit, "custom JSON encoding of "+typ.String())
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types.Struct:
+		values := make(map[int]interface{}, len(lit.Elts))
+		for i, element := range lit.Elts {
+			index := i
+			if kv, ok := element.(*ast.KeyValueExpr); ok {
+				index = fieldIndex(u, kv.Key.(*ast.Ident).Name)
+				element = kv.Value
+			}
+			value, err := l.eval(pkg, element)
+			if err != nil {
+				return nil, err
+			}
+			values[index] = value
+		}
+		return l.object(u, values), nil
+	case *types.Slice, *types.Array:
+		var length int64
+		var elem types.Type
+		if array, ok := u.(*types.Array); ok {
+			length, elem = array.Len(), array.Elem()
+		} else {
+			elem = u.(*types.Slice).Elem()
+		}
+		values := make(map[int64]interface{}, len(lit.Elts))
+		var index int64
+		for _, element := range lit.Elts {
+			if kv, ok := element.(*ast.KeyValueExpr); ok {
+				index, _ = constant.Int64Val(pkg.TypesInfo.Types[kv.Key].Value)
+				element = kv.Value
+			}
+			value, err := l.eval(pkg, element)
+			if err != nil {
+				return nil, err
+			}
+			values[index] = value
+			index++
+			if index > length {
+				length = index
+			}
+		}
+		if _, isSlice := u.(*types.Slice); isSlice && isByte(elem) {
+			// encoding/json writes byte slices as base64 string
+			bytes := make([]byte, length)
+			for i, value := range values {
+				n, _ := value.(int64)
+				bytes[i] = byte(n)
+			}
+			return base64.StdEncoding.EncodeToString(bytes), nil
+		}
+		array := make([]interface{}, length)
+		for i := range array {
+			if value, exists := values[int64(i)]; exists {
+				array[i] = value
+			} else {
+				array[i] = l.zero(elem)
+			}
+		}
+		return array, nil
+	case *types.Map:
+		object := make(map[string]interface{}, len(lit.Elts))
+		for _, element := range lit.Elts {
+			kv := element.(*ast.KeyValueExpr)
+			key, err := l.eval(pkg, kv.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := l.eval(pkg, kv.Value)
+			if err != nil {
+				return nil, err
+			}
+			switch k := key.(type) {
+			case string:
+				object[k] = value
+			case int64, uint64:
+				object[fmt.Sprint(k)] = value
+			default:
+				return nil, l.unsupported(pkg, kv.Key, "map key")
+			}
+		}
+		return object, nil
+	}
+
+	return nil, l.unsupported(pkg, lit, "")
+}
+
+// call evaluates conversions and time.Date with constant arguments
+func (l *literalEvaluator) call(pkg *packages.Package, call *ast.CallExpr) (interface{}, error) {
+	if pkg.TypesInfo.Types[call.Fun].IsType() && len(call.Args) == 1 {
+		typ := pkg.TypesInfo.Types[call].Type
+		if hasCustomEncoding(typ) {
+			return nil, l.unsupported(pkg, call, "custom JSON encoding of "+typ.String())
+		}
+		value, err := l.eval(pkg, call.Args[0])
+		if s, isString := value.(string); isString && isByteSlice(typ) {
+			return base64.StdEncoding.EncodeToString([]byte(s)), err
+		}
+		return value, err
+	}
+
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, l.unsupported(pkg, call, "")
+	}
+	function, ok := pkg.TypesInfo.Uses[selector.Sel].(*types.Func)
+	if !ok || function.Pkg() == nil || function.Pkg().Path() != "time" || function.Name() != "Date" {
+		return nil, l.unsupported(pkg, call, "")
+	}
+
+	if len(call.Args) != 8 {
+		return nil, l.unsupported(pkg, call, "")
+	}
+	var parts [7]int
+	for i := range parts {
+		value := pkg.TypesInfo.Types[call.Args[i]].Value
+		if value == nil {
+			return nil, l.unsupported(pkg, call.Args[i], "non-constant argument")
+		}
+		n, _ := constant.Int64Val(value)
+		parts[i] = int(n)
+	}
+	location, ok := call.Args[7].(*ast.SelectorExpr)
+	if !ok || !isTimeUTC(pkg.TypesInfo.Uses[location.Sel]) {
+		return nil, l.unsupported(pkg, call.Args[7], "location other than time.UTC")
+	}
+
+	date := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], parts[6], time.UTC)
+	return date.Format(time.RFC3339Nano), nil
+}
+
+// object returns the JSON object of the struct whose fields are given by their index, fields without value have
+// their zero value. Like encoding/json the fields of embedded structs without tag name are promoted and unexported
+// fields skipped, an embedded struct with tag name is a field.
+func (l *literalEvaluator) object(s *types.Struct, values map[int]interface{}) map[string]interface{} {
+	object := make(map[string]interface{})
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		value, set := values[i]
+		if !set {
+			value = l.zero(field.Type())
+		}
+
+		name, options := fieldTag(s.Tag(i), l.structTag)
+		if name == "-" && len(options) == 0 {
+			continue
+		}
+		if field.Embedded() && len(name) == 0 {
+			if embedded, ok := value.(map[string]interface{}); ok {
+				for key, v := range embedded {
+					if _, exists := object[key]; !exists {
+						object[key] = v
+					}
+				}
+			}
+			continue
+		}
+		if !field.Exported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name()
+		}
+		if isEmpty(value) && hasOption(options, "omitempty") && !isStruct(field.Type()) {
+			continue
+		}
+		object[name] = value
+	}
+
+	return object
+}
+
+// zero returns the JSON value of the zero value of the type
+func (l *literalEvaluator) zero(typ types.Type) interface{} {
+	if zero, exists := zeroEncodings[qualifiedName(typ)]; exists {
+		return zero
+	}
+	if hasCustomEncoding(typ) {
+		// the zero value of other types with a custom encoding is unknown, the example of their mapping is used
+		if mapping, exists := l.typeRegistry.Lookup(qualifiedName(typ)); exists {
+			return mapping.Example
+		}
+		return nil
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return false
+		case u.Info()&types.IsString != 0:
+			return ""
+		case u.Info()&types.IsFloat != 0:
+			return float64(0)
+		case u.Info()&types.IsNumeric != 0:
+			return int64(0)
+		}
+	case *types.Struct:
+		return l.object(u, nil)
+	case *types.Array:
+		array := make([]interface{}, u.Len())
+		for i := range array {
+			array[i] = l.zero(u.Elem())
+		}
+		return array
+	}
+
+	return nil
+}
+
+// unsupported returns the error of an expression which cannot be evaluated
+func (l *literalEvaluator) unsupported(pkg *packages.Package, expr ast.Expr, reason string) error {
+	if len(reason) > 0 {
+		reason = ", " + reason
+	}
+	return fmt.Errorf("%s: unsupported expression %s%s", pkg.Fset.Position(expr.Pos()), types.ExprString(expr), reason)
+}
+
+// constantValue converts the constant to its JSON value
+func constantValue(pkg *packages.Package, expr ast.Expr, typ types.Type, value constant.Value) (interface{}, error) {
+	switch value.Kind() {
+	case constant.Bool:
+		return constant.BoolVal(value), nil
+	case constant.String:
+		return constant.StringVal(value), nil
+	case constant.Int:
+		if n, exact := constant.Int64Val(value); exact {
+			return n, nil
+		}
+		if n, exact := constant.Uint64Val(value); exact {
+			return n, nil
+		}
+	case constant.Float:
+		f, _ := constant.Float64Val(value)
+		if basic, ok := typ.Underlying().(*types.Basic); ok && basic.Kind() == types.Float32 {
+			// encoding/json formats float32 values with 32 bit precision
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 32)), nil
+		}
+		if !math.IsInf(f, 0) {
+			return f, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: unsupported constant %s", pkg.Fset.Position(expr.Pos()), value)
+}
+
+// fieldIndex returns the index of the named field of the struct
+func fieldIndex(s *types.Struct, name string) int {
+	for i := 0; i < s.NumFields(); i++ {
+		if s.Field(i).Name() == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// qualifiedName returns the fully qualified name of a named type, e.g. time.Time, otherwise an empty string
+func qualifiedName(typ types.Type) string {
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
+
+// hasCustomEncoding reports whether the type implements json.Marshaler or encoding.TextMarshaler, whose encoding
+// cannot be derived from the literal
+func hasCustomEncoding(typ types.Type) bool {
+	if _, ok := typ.(*types.Named); !ok {
+		return false
+	}
+	methods := types.NewMethodSet(types.NewPointer(typ))
+	for _, name := range []string{"MarshalJSON", "MarshalText"} {
+		if methods.Lookup(nil, name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEmpty reports whether encoding/json considers the value empty for omitempty
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return len(v) == 0
+	case int64:
+		return v == 0
+	case uint64:
+		return v == 0
+	case float64:
+		return v == 0
+	case json.Number:
+		f, _ := v.Float64()
+		return f == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+
+	return false
+}
+
+// isByte reports whether the type is byte respectively uint8
+func isByte(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+// isByteSlice reports whether the type is a byte slice, which encoding/json writes as base64 string
+func isByteSlice(typ types.Type) bool {
+	slice, ok := typ.Underlying().(*types.Slice)
+	return ok && isByte(slice.Elem())
+}
+
+// isTimeUTC reports whether the object is the variable time.UTC
+func isTimeUTC(object types.Object) bool {
+	return object != nil && object.Pkg() != nil && object.Pkg().Path() == "time" && object.Name() == "UTC"
+}
+
+// isStruct reports whether the type is a struct, which omitempty never omits
+func isStruct(typ types.Type) bool {
+	_, ok := typ.Underlying().(*types.Struct)
+	return ok
+}
+
+// fieldTag returns the name of the field in the struct tag and the options, e.g. omitempty
+func fieldTag(tag string, key string) (string, []string) {
+	parts := strings.Split(reflect.StructTag(tag).Get(key), ",")
+	return parts[0], parts[1:]
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
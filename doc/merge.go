@@ -0,0 +1,146 @@
+package doc
+
+import (
+	"github.com/go-openapi/spec"
+	"gopkg.in/yaml.v3"
+	"os"
+	"reflect"
+)
+
+// GeneratedExtension marks the component schemas written by the generator
+const GeneratedExtension = "x-generated"
+
+// MergeReport lists the component schemas affected by a merge
+type MergeReport struct {
+	// Added are the generated schemas not contained in the existing document
+	Added []string
+	// Updated are the previously generated schemas which changed
+	Updated []string
+	// Unchanged are the previously generated schemas which did not change
+	Unchanged []string
+	// Stale are the previously generated schemas which are no longer generated
+	Stale []string
+	// Conflicts are the hand-written schemas having the name of a generated schema, they are left untouched
+	Conflicts []string
+}
+
+// MergeSchemas adds the schemas to the components of the existing OpenAPI document. Only schemas marked with
+// the x-generated extension are replaced, everything else including the key order and, for YAML, comments is kept.
+// Previously generated schemas which are no longer generated are reported as stale and removed if prune is set.
+// The format of the returned document is detected from the existing content.
+func MergeSchemas(existing []byte, schemas []spec.Schema, prune bool) ([]byte, *MergeReport, error) {
+	document, err := parseNode(existing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report, err := mergeSchemaNodes(document.Content[0], schemas, prune)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err := encodeNode(document, detectFormat(existing))
+	return out, report, err
+}
+
+// MergeSchemasIntoFile merges the schemas into the OpenAPI document of the given file, see MergeSchemas.
+// A missing file is created as a new Document in the format derived from its extension.
+func MergeSchemasIntoFile(path string, schemas []spec.Schema, prune bool) (*MergeReport, error) {
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		document, err := NewDocument("", Info{}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if existing, err = document.Encode(FormatFromPath(path)); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	out, report, err := MergeSchemas(existing, schemas, prune)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, WriteFileAtomic(path, out)
+}
+
+func mergeSchemaNodes(root *yaml.Node, schemas []spec.Schema, prune bool) (*MergeReport, error) {
+	report := &MergeReport{}
+	components := ensureMapping(ensureMapping(root, "components"), "schemas")
+
+	names, generated := generatedSchemas(schemas)
+
+	for _, name := range names {
+		node, err := toNode(generated[name])
+		if err != nil {
+			return nil, err
+		}
+
+		i := mappingIndex(components, name)
+		switch {
+		case i < 0:
+			components.Content = append(components.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, node)
+			report.Added = append(report.Added, name)
+		case !isGenerated(components.Content[i+1]):
+			report.Conflicts = append(report.Conflicts, name)
+		case equalNodes(components.Content[i+1], node):
+			report.Unchanged = append(report.Unchanged, name)
+		default:
+			// keep the comments of the existing schema
+			node.HeadComment = components.Content[i+1].HeadComment
+			node.LineComment = components.Content[i+1].LineComment
+			node.FootComment = components.Content[i+1].FootComment
+			components.Content[i+1] = node
+			report.Updated = append(report.Updated, name)
+		}
+	}
+
+	var content []*yaml.Node
+	for i := 0; i+1 < len(components.Content); i += 2 {
+		name := components.Content[i].Value
+		if _, exists := generated[name]; !exists && isGenerated(components.Content[i+1]) {
+			report.Stale = append(report.Stale, name)
+			if prune {
+				continue
+			}
+		}
+		content = append(content, components.Content[i], components.Content[i+1])
+	}
+	components.Content = content
+
+	return report, nil
+}
+
+// generatedSchemas returns the component schemas marked with the x-generated extension by their sorted names
+func generatedSchemas(schemas []spec.Schema) ([]string, map[string]spec.Schema) {
+	names, generated := componentSchemas(schemas)
+	for name, schema := range generated {
+		schema.AddExtension(GeneratedExtension, true)
+		generated[name] = schema
+	}
+
+	return names, generated
+}
+
+// isGenerated returns whether the schema node is marked with the x-generated extension
+func isGenerated(node *yaml.Node) bool {
+	value := mappingValue(node, GeneratedExtension)
+	return value != nil && value.Value == "true"
+}
+
+// equalNodes compares the nodes semantically, i.e. ignoring key order and formatting
+func equalNodes(a, b *yaml.Node) bool {
+	va, err := fromNode(a)
+	if err != nil {
+		return false
+	}
+	vb, err := fromNode(b)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
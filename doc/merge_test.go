@@ -0,0 +1,171 @@
+package doc
+
+import (
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var mergeTestSchemas = []spec.Schema{
+	{SchemaProps: spec.SchemaProps{ID: "Added", Type: []string{"object"}}},
+	{SchemaProps: spec.SchemaProps{ID: "Updated", Type: []string{"string"}}},
+	{SchemaProps: spec.SchemaProps{ID: "Unchanged", Type: []string{"integer"}}},
+	{SchemaProps: spec.SchemaProps{ID: "Manual", Type: []string{"boolean"}}},
+}
+
+func Test_MergeSchemas_YAML(t *testing.T) {
+	existing := `# hand-written API
+openapi: 3.0.3
+info:
+  title: Test # inline comment
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    # written by hand
+    Manual:
+      type: object
+    Updated:
+      type: object
+      x-generated: true
+    Unchanged:
+      type: integer
+      x-generated: true
+    Stale:
+      type: object
+      x-generated: true
+`
+	out, report, err := MergeSchemas([]byte(existing), mergeTestSchemas, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &MergeReport{
+		Added:     []string{"Added"},
+		Updated:   []string{"Updated"},
+		Unchanged: []string{"Unchanged"},
+		Stale:     []string{"Stale"},
+		Conflicts: []string{"Manual"},
+	}, report)
+	assert.Equal(t, `# hand-written API
+openapi: 3.0.3
+info:
+  title: Test # inline comment
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    # written by hand
+    Manual:
+      type: object
+    Updated:
+      type: string
+      x-generated: true
+    Unchanged:
+      type: integer
+      x-generated: true
+    Stale:
+      type: object
+      x-generated: true
+    Added:
+      type: object
+      x-generated: true
+`, string(out))
+
+	out, report, err = MergeSchemas([]byte(existing), mergeTestSchemas, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Stale"}, report.Stale)
+	assert.NotContains(t, string(out), "Stale")
+}
+
+func Test_MergeSchemas_JSON(t *testing.T) {
+	existing := `{"openapi": "3.0.3", "paths": {"/b": {}, "/a": {}}, "info": {"version": "1.0", "title": "Test"}}`
+
+	out, report, err := MergeSchemas([]byte(existing), mergeTestSchemas[:1], false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Added"}, report.Added)
+	assert.Equal(t, `{
+  "openapi": "3.0.3",
+  "paths": {
+    "/b": {},
+    "/a": {}
+  },
+  "info": {
+    "version": "1.0",
+    "title": "Test"
+  },
+  "components": {
+    "schemas": {
+      "Added": {
+        "type": "object",
+        "x-generated": true
+      }
+    }
+  }
+}
+`, string(out))
+}
+
+func Test_MergeSchemas_EmptyComponents(t *testing.T) {
+	for _, existing := range []string{
+		"openapi: 3.0.3\ncomponents:\n",
+		"openapi: 3.0.3\ncomponents:\n  schemas:\n",
+		"openapi: 3.0.3\ncomponents:\n  schemas: ~\n",
+	} {
+		out, report, err := MergeSchemas([]byte(existing), mergeTestSchemas[:1], false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Added"}, report.Added)
+		assert.Equal(t, `openapi: 3.0.3
+components:
+  schemas:
+    Added:
+      type: object
+      x-generated: true
+`, string(out))
+	}
+}
+
+func Test_MergeSchemasIntoFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.json")
+
+	report, err := MergeSchemasIntoFile(path, mergeTestSchemas[:1], false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Added"}, report.Added)
+
+	report, err = MergeSchemasIntoFile(path, mergeTestSchemas[:1], false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Added"}, report.Unchanged)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"x-generated": true`)
+	// the file is replaced atomically without leaving temporary files
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func Test_MergeSchemasIntoFile_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+
+	_, err := MergeSchemasIntoFile(path, mergeTestSchemas[:1], false)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `components:
+  schemas:
+    Added:
+      type: object
+      x-generated: true
+`)
+}
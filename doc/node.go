@@ -0,0 +1,234 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// parseNode parses a YAML or JSON document into its document node
+func parseNode(content []byte) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, err
+	}
+	if node.Kind == 0 {
+		// empty document
+		node = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if node.Kind != yaml.DocumentNode || len(node.Content) != 1 || node.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("document is not an object")
+	}
+
+	return &node, nil
+}
+
+// toNode converts a JSON marshallable value to a node in YAML block style
+func toNode(v interface{}) (*yaml.Node, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, err
+	}
+	clearStyle(&node)
+
+	return node.Content[0], nil
+}
+
+// fromNode decodes the node into a generic JSON value
+func fromNode(node *yaml.Node) (interface{}, error) {
+	var out bytes.Buffer
+	if err := writeNodeJSON(&out, node, "", ""); err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err := json.Unmarshal(out.Bytes(), &v)
+	return v, err
+}
+
+// encodeNode encodes the document node in the given format keeping the key order and, for YAML, the comments
+func encodeNode(node *yaml.Node, format Format) ([]byte, error) {
+	var out bytes.Buffer
+	switch format {
+	case JSONFormat:
+		if err := writeNodeJSON(&out, node, "", "  "); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+	case YAMLFormat:
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(node); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	return out.Bytes(), nil
+}
+
+// writeNodeJSON writes the node as JSON keeping the key order of mappings
+func writeNodeJSON(out *bytes.Buffer, node *yaml.Node, prefix, indent string) error {
+	newline := func(level string) {
+		if len(indent) > 0 {
+			out.WriteByte('\n')
+			out.WriteString(level)
+		}
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			out.WriteString("null")
+			return nil
+		}
+		return writeNodeJSON(out, node.Content[0], prefix, indent)
+	case yaml.AliasNode:
+		return writeNodeJSON(out, node.Alias, prefix, indent)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			out.WriteString("{}")
+			return nil
+		}
+		out.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			newline(prefix + indent)
+			key, _ := json.Marshal(node.Content[i].Value)
+			out.Write(key)
+			out.WriteByte(':')
+			if len(indent) > 0 {
+				out.WriteByte(' ')
+			}
+			if err := writeNodeJSON(out, node.Content[i+1], prefix+indent, indent); err != nil {
+				return err
+			}
+		}
+		newline(prefix)
+		out.WriteByte('}')
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			out.WriteString("[]")
+			return nil
+		}
+		out.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			newline(prefix + indent)
+			if err := writeNodeJSON(out, item, prefix+indent, indent); err != nil {
+				return err
+			}
+		}
+		newline(prefix)
+		out.WriteByte(']')
+	case yaml.ScalarNode:
+		return writeScalarJSON(out, node)
+	default:
+		return fmt.Errorf("unknown node kind %d", node.Kind)
+	}
+
+	return nil
+}
+
+func writeScalarJSON(out *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!null":
+		out.WriteString("null")
+		return nil
+	case "!!bool", "!!int", "!!float":
+		var v interface{}
+		if err := node.Decode(&v); err != nil {
+			return err
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		out.Write(raw)
+		return nil
+	}
+
+	raw, err := json.Marshal(node.Value)
+	if err != nil {
+		return err
+	}
+	out.Write(raw)
+	return nil
+}
+
+// mappingValue returns the value node of the given key of a mapping node or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(node, key); i >= 0 {
+		return node.Content[i+1]
+	}
+
+	return nil
+}
+
+// mappingIndex returns the index of the key node of the given key in a mapping node or -1
+func mappingIndex(node *yaml.Node, key string) int {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// ensureMapping returns the mapping value of the given key, a missing key is appended to the mapping node.
+// A value which is no mapping, e.g. null of a key without value, is replaced by an empty mapping.
+func ensureMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := mappingValue(node, key); value != nil {
+		if value.Kind != yaml.MappingNode {
+			*value = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: value.HeadComment, LineComment: value.LineComment, FootComment: value.FootComment}
+		}
+		if len(value.Content) == 0 {
+			// an empty mapping is written as {}, its entries should be written in block style
+			value.Style &^= yaml.FlowStyle
+		}
+		return value
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// detectFormat returns the format of the given content, JSON documents start with a curly bracket
+func detectFormat(content []byte) Format {
+	if strings.HasPrefix(strings.TrimSpace(string(content)), "{") {
+		return JSONFormat
+	}
+
+	return YAMLFormat
+}
+
+// clearStyle resets the flow style of JSON parsed nodes to the YAML block style
+func clearStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		node.Style &^= yaml.DoubleQuotedStyle
+	}
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
@@ -0,0 +1,113 @@
+package doc
+
+import (
+	"log"
+	"regexp"
+)
+
+// Option configures optional behaviour of the Generator
+type Option func(o *openapiGenerator)
+
+// EmbeddedStructMode defines how fields of embedded structs are rendered
+type EmbeddedStructMode int
+
+const (
+	// FlattenEmbeddedStructs copies the fields of embedded structs into the properties of the embedding struct
+	FlattenEmbeddedStructs EmbeddedStructMode = iota
+	// ComposeEmbeddedStructs renders embedded structs as own schema referenced via allOf by the embedding struct
+	ComposeEmbeddedStructs
+)
+
+// WithEmbeddedStructMode sets how embedded structs are rendered, defaults to FlattenEmbeddedStructs
+func WithEmbeddedStructMode(mode EmbeddedStructMode) Option {
+	return func(o *openapiGenerator) {
+		o.embeddedStructMode = mode
+	}
+}
+
+// WithSchemaVariants derives the given variants, e.g. ItemCreate or ItemPatch, for each generated struct schema
+func WithSchemaVariants(variants ...SchemaVariant) Option {
+	return func(o *openapiGenerator) {
+		o.schemaVariants = append(o.schemaVariants, variants...)
+	}
+}
+
+// WithTypeMapping maps the fully qualified Go type, e.g. github.com/google/uuid.UUID, to a fixed schema
+func WithTypeMapping(goType string, mapping TypeMapping) Option {
+	return func(o *openapiGenerator) {
+		o.typeRegistry.Register(goType, mapping)
+	}
+}
+
+// WithExclude skips all structs whose name matches the given regular expression even if matched by the filter
+func WithExclude(exclude *regexp.Regexp) Option {
+	return func(o *openapiGenerator) {
+		o.exclude = exclude
+	}
+}
+
+// WithLogger sets the logger for progress and warning messages, defaults to a logger writing to stderr
+func WithLogger(logger *log.Logger) Option {
+	return func(o *openapiGenerator) {
+		o.logger = logger
+	}
+}
+
+// WithDir sets the directory relative package patterns are resolved in, defaults to the current working directory
+func WithDir(dir string) Option {
+	return func(o *openapiGenerator) {
+		o.dir = dir
+	}
+}
+
+// WithGoTypeExtension marks each generated struct schema with the x-go-type extension naming its Go type,
+// e.g. to map the components of an existing document to the Go types they describe
+func WithGoTypeExtension() Option {
+	return func(o *openapiGenerator) {
+		o.goTypeExtension = true
+	}
+}
+
+// WithExampleFunctions loads the test files of the packages and uses the JSON output of Example functions, e.g.
+// ExampleOrder or ExampleOrder_json, as example of the struct they are named after
+func WithExampleFunctions() Option {
+	return func(o *openapiGenerator) {
+		o.exampleFunctions = true
+	}
+}
+
+// WithExamples sets a synthesized example on every generated schema without @example, see SynthesizeExamples
+func WithExamples() Option {
+	return func(o *openapiGenerator) {
+		o.examples = true
+	}
+}
+
+// WithWorkers sets the number of packages traversed concurrently, defaults to GOMAXPROCS. The generated schemas do
+// not depend on the number of workers.
+func WithWorkers(workers int) Option {
+	return func(o *openapiGenerator) {
+		o.workers = workers
+	}
+}
+
+// WithScope loads the packages of the scope besides the documented packages, see Scope
+func WithScope(scope *Scope) Option {
+	return func(o *openapiGenerator) {
+		o.scope = scope
+	}
+}
+
+// WithCache reuses the schemas of unchanged packages stored in the cache and stores the schemas of the others
+func WithCache(cache *Cache) Option {
+	return func(o *openapiGenerator) {
+		o.cache = cache
+	}
+}
+
+// WithSession shares the session by all DocumentStruct calls of the generator, by default every call uses a new session
+func WithSession(session *Session) Option {
+	return func(o *openapiGenerator) {
+		o.session = session
+	}
+}
@@ -0,0 +1,227 @@
+package doc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// OverlayVersion is the version of the OpenAPI Overlay specification of the emitted overlays
+const OverlayVersion = "1.0.0"
+
+const schemasJSONPath = "$.components.schemas"
+
+// OverlayInfo is the info object of an Overlay
+type OverlayInfo struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// OverlayAction is an action of an Overlay, either updating or removing the nodes selected by the target
+type OverlayAction struct {
+	Target      string      `json:"target" yaml:"target"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Update      interface{} `json:"update,omitempty" yaml:"update,omitempty"`
+	Remove      bool        `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+// Overlay is an OpenAPI Overlay document
+type Overlay struct {
+	Overlay string          `json:"overlay" yaml:"overlay"`
+	Info    OverlayInfo     `json:"info" yaml:"info"`
+	Extends string          `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Actions []OverlayAction `json:"actions" yaml:"actions"`
+	// Conflicts are the hand-written schemas of the existing document named like a generated schema, which are kept
+	Conflicts []string `json:"-" yaml:"-"`
+}
+
+// NewOverlay returns an overlay writing the generated schemas to the components of an OpenAPI document.
+// If the existing document is given, only added, changed and stale generated schemas result in actions. Since
+// updates are merged into the target, a changed schema is removed before it is added again. Like MergeSchemas
+// hand-written schemas are skipped and reported as conflicts. Without existing document every schema is only
+// updated, which keeps the keywords of schemas of the same name missing in the generated schema.
+func NewOverlay(info OverlayInfo, schemas []spec.Schema, existing []byte) (*Overlay, error) {
+	if len(info.Title) == 0 {
+		info.Title = "Generated schemas"
+	}
+	if len(info.Version) == 0 {
+		info.Version = defaultInfoVersion
+	}
+	overlay := &Overlay{Overlay: OverlayVersion, Info: info, Actions: []OverlayAction{}}
+
+	var components *yaml.Node
+	if existing != nil {
+		document, err := parseNode(existing)
+		if err != nil {
+			return nil, err
+		}
+		components = mappingValue(mappingValue(document.Content[0], "components"), "schemas")
+	}
+
+	names, generated := generatedSchemas(schemas)
+
+	for _, name := range names {
+		schema := generated[name]
+		target := schemasJSONPath + jsonPathName(name)
+		if existing != nil {
+			current := mappingValue(components, name)
+			if current != nil {
+				node, err := toNode(schema)
+				if err != nil {
+					return nil, err
+				}
+				if equalNodes(current, node) {
+					continue
+				}
+				if !isGenerated(current) {
+					overlay.Conflicts = append(overlay.Conflicts, name)
+					continue
+				}
+				overlay.Actions = append(overlay.Actions, OverlayAction{Target: target, Description: "remove changed schema " + name, Remove: true})
+			}
+		}
+		overlay.Actions = append(overlay.Actions, updateSchemaAction(name, schema, components != nil))
+	}
+
+	if components != nil {
+		for i := 0; i+1 < len(components.Content); i += 2 {
+			name := components.Content[i].Value
+			if _, exists := generated[name]; !exists && isGenerated(components.Content[i+1]) {
+				overlay.Actions = append(overlay.Actions, OverlayAction{Target: schemasJSONPath + jsonPathName(name), Description: "remove stale schema " + name, Remove: true})
+			}
+		}
+	}
+
+	return overlay, nil
+}
+
+// updateSchemaAction returns the action adding the schema to the components. If the document may lack
+// the components the root is updated, since an update of a missing target has no effect.
+func updateSchemaAction(name string, schema spec.Schema, hasSchemas bool) OverlayAction {
+	if hasSchemas {
+		return OverlayAction{Target: schemasJSONPath, Description: "add schema " + name, Update: map[string]interface{}{name: schema}}
+	}
+
+	return OverlayAction{
+		Target:      "$",
+		Description: "add schema " + name,
+		Update:      map[string]interface{}{"components": map[string]interface{}{"schemas": map[string]interface{}{name: schema}}},
+	}
+}
+
+// Encode returns the pretty printed overlay in the given format
+func (o *Overlay) Encode(format Format) ([]byte, error) {
+	return encode(o, format)
+}
+
+// ParseOverlay parses an overlay document in YAML or JSON
+func ParseOverlay(content []byte) (*Overlay, error) {
+	overlay := &Overlay{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	if err := decoder.Decode(overlay); err != nil {
+		return nil, err
+	}
+	if len(overlay.Overlay) == 0 {
+		return nil, errors.New("document is not an overlay")
+	}
+
+	return overlay, nil
+}
+
+// ApplyOverlay applies the actions of the overlay to the OpenAPI document in order and returns the resulting
+// document in the format of the given document. Key order and, for YAML, comments of the document are kept.
+func ApplyOverlay(document []byte, overlay *Overlay) ([]byte, error) {
+	root, err := parseNode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, action := range overlay.Actions {
+		if err := applyAction(root.Content[0], action); err != nil {
+			return nil, fmt.Errorf("action %d (%s): %w", i, action.Target, err)
+		}
+	}
+
+	return encodeNode(root, detectFormat(document))
+}
+
+func applyAction(root *yaml.Node, action OverlayAction) error {
+	matches, err := evalJSONPath(root, action.Target)
+	if err != nil {
+		return err
+	}
+
+	if action.Remove {
+		// remove from the back to keep the indices of the remaining matches valid
+		for i := len(matches) - 1; i >= 0; i-- {
+			match := matches[i]
+			switch {
+			case match.parent == nil:
+				return errors.New("the root cannot be removed")
+			case match.parent.Kind == yaml.MappingNode:
+				match.parent.Content = append(match.parent.Content[:match.index-1], match.parent.Content[match.index+1:]...)
+			default:
+				match.parent.Content = append(match.parent.Content[:match.index], match.parent.Content[match.index+1:]...)
+			}
+		}
+		return nil
+	}
+
+	if action.Update == nil {
+		return nil
+	}
+	update, err := toNode(action.Update)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		mergeNode(match.node, update)
+	}
+
+	return nil
+}
+
+// mergeNode merges the update into the target: mappings are merged recursively, sequences are concatenated
+// and any other value replaces the target
+func mergeNode(target, update *yaml.Node) {
+	if len(target.Content) == 0 {
+		// an empty mapping or sequence is written in flow style, its new entries should be written in block style
+		target.Style &^= yaml.FlowStyle
+	}
+
+	switch {
+	case target.Kind == yaml.MappingNode && update.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(update.Content); i += 2 {
+			key, value := update.Content[i], update.Content[i+1]
+			if j := mappingIndex(target, key.Value); j >= 0 {
+				current := target.Content[j+1]
+				if (current.Kind == yaml.MappingNode || current.Kind == yaml.SequenceNode) && current.Kind == value.Kind {
+					mergeNode(current, value)
+				} else {
+					target.Content[j+1] = copyNode(value)
+				}
+			} else {
+				target.Content = append(target.Content, copyNode(key), copyNode(value))
+			}
+		}
+	case target.Kind == yaml.SequenceNode && update.Kind == yaml.SequenceNode:
+		for _, item := range update.Content {
+			target.Content = append(target.Content, copyNode(item))
+		}
+	default:
+		*target = *copyNode(update)
+	}
+}
+
+// copyNode returns a deep copy of the node
+func copyNode(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = nil
+	for _, child := range node.Content {
+		copied.Content = append(copied.Content, copyNode(child))
+	}
+
+	return &copied
+}
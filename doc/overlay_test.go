@@ -0,0 +1,144 @@
+package doc
+
+import (
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const overlayTestDocument = `openapi: 3.0.3
+info:
+  title: Test
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    # written by hand
+    Manual:
+      type: object
+    Updated:
+      type: object
+      required: [a]
+      x-generated: true
+    Unchanged:
+      type: integer
+      x-generated: true
+    Stale:
+      type: object
+      x-generated: true
+`
+
+func Test_NewOverlay(t *testing.T) {
+	overlay, err := NewOverlay(OverlayInfo{}, mergeTestSchemas[:3], []byte(overlayTestDocument))
+	assert.NoError(t, err)
+
+	out, err := overlay.Encode(YAMLFormat)
+	assert.NoError(t, err)
+	assert.Equal(t, `overlay: 1.0.0
+info:
+  title: Generated schemas
+  version: 1.0.0
+actions:
+  - target: $.components.schemas
+    description: add schema Added
+    update:
+      Added:
+        type: object
+        x-generated: true
+  - target: $.components.schemas.Updated
+    description: remove changed schema Updated
+    remove: true
+  - target: $.components.schemas
+    description: add schema Updated
+    update:
+      Updated:
+        type: string
+        x-generated: true
+  - target: $.components.schemas.Stale
+    description: remove stale schema Stale
+    remove: true
+`, string(out))
+
+	// the hand-written schema is kept like by MergeSchemas
+	overlay, err = NewOverlay(OverlayInfo{}, mergeTestSchemas[3:], []byte(overlayTestDocument))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Manual"}, overlay.Conflicts)
+	for _, action := range overlay.Actions {
+		assert.NotContains(t, action.Description, "Manual")
+	}
+}
+
+func Test_ApplyOverlay(t *testing.T) {
+	overlay, err := NewOverlay(OverlayInfo{}, mergeTestSchemas[:3], []byte(overlayTestDocument))
+	assert.NoError(t, err)
+	out, err := overlay.Encode(JSONFormat)
+	assert.NoError(t, err)
+
+	parsed, err := ParseOverlay(out)
+	assert.NoError(t, err)
+	applied, err := ApplyOverlay([]byte(overlayTestDocument), parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, `openapi: 3.0.3
+info:
+  title: Test
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    # written by hand
+    Manual:
+      type: object
+    Unchanged:
+      type: integer
+      x-generated: true
+    Added:
+      type: object
+      x-generated: true
+    Updated:
+      type: string
+      x-generated: true
+`, string(applied))
+}
+
+func Test_ApplyOverlay_WithoutExistingDocument(t *testing.T) {
+	schemas := []spec.Schema{{SchemaProps: spec.SchemaProps{ID: "My Schema", Type: []string{"object"}}}}
+	overlay, err := NewOverlay(OverlayInfo{Title: "Test", Version: "2.0.0"}, schemas, nil)
+	assert.NoError(t, err)
+	assert.Len(t, overlay.Actions, 1)
+	assert.Equal(t, "$", overlay.Actions[0].Target)
+
+	applied, err := ApplyOverlay([]byte(`{"openapi": "3.0.3"}`), overlay)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.0.3",
+		"components": {"schemas": {"My Schema": {"type": "object", "x-generated": true}}}
+	}`, string(applied))
+
+	// a hand-written schema of the same name is not removed
+	applied, err = ApplyOverlay([]byte(`{"openapi": "3.0.3", "components": {"schemas": {"My Schema": {"description": "by hand"}}}}`), overlay)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.0.3",
+		"components": {"schemas": {"My Schema": {"description": "by hand", "type": "object", "x-generated": true}}}
+	}`, string(applied))
+}
+
+func Test_ParseJSONPath(t *testing.T) {
+	segments, err := parseJSONPath(`$.components.schemas['My \'Schema\''].required[0].*`)
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonPathSegment{
+		{name: "components"},
+		{name: "schemas"},
+		{name: "My 'Schema'"},
+		{name: "required"},
+		{index: 0, isIndex: true},
+		{name: "*", wildcard: true},
+	}, segments)
+
+	_, err = parseJSONPath(`$..schemas`)
+	assert.Error(t, err)
+	_, err = parseJSONPath(`$.paths[?(@.get)]`)
+	assert.Error(t, err)
+	_, err = parseJSONPath(`components`)
+	assert.Error(t, err)
+}
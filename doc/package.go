@@ -2,13 +2,23 @@ package doc
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
 	"go/token"
 	"golang.org/x/tools/go/packages"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 )
 
-// loadPackages loads and returns the named Go packages
-func loadPackages(_package ...string) ([]*packages.Package, error) {
-	cfg := &packages.Config{Fset: token.NewFileSet(), Mode: packages.NeedTypes | packages.NeedSyntax}
+// loadPackages loads and returns the named Go packages. Relative package patterns are resolved in dir,
+// an empty dir denotes the current working directory. The imports of the packages are loaded transitively with
+// their files and types but without syntax, which is parsed on demand by the packageIndex. NeedDeps is not set
+// since it would type-check every dependency from source.
+func loadPackages(dir string, _package ...string) ([]*packages.Package, error) {
+	cfg := &packages.Config{Dir: dir, Fset: token.NewFileSet(), Mode: packages.NeedName | packages.NeedFiles |
+		packages.NeedImports | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo}
 	pkgs, err := packages.Load(cfg, _package...)
 	if err != nil {
 		return nil, err
@@ -18,3 +28,190 @@ func loadPackages(_package ...string) ([]*packages.Package, error) {
 	}
 	return pkgs, nil
 }
+
+// packageIndex holds the loaded packages and their transitive imports by path. It is safe for concurrent use.
+type packageIndex struct {
+	fset     *token.FileSet
+	mu       sync.Mutex
+	packages map[string]*packages.Package
+	// parsed holds the dependencies whose syntax is parsed by path
+	parsed map[string]*parsedPackage
+}
+
+// parsedPackage is a dependency whose syntax is parsed once
+type parsedPackage struct {
+	once sync.Once
+	pkg  *packages.Package
+	err  error
+}
+
+func newPackageIndex() *packageIndex {
+	return &packageIndex{
+		fset:     token.NewFileSet(),
+		packages: make(map[string]*packages.Package),
+		parsed:   make(map[string]*parsedPackage),
+	}
+}
+
+// add indexes the packages and their imports, packages with syntax replace the same package indexed as import
+func (i *packageIndex) add(pkgs ...*packages.Package) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	packages.Visit(pkgs, func(pkg *packages.Package) bool {
+		if existing, exists := i.packages[pkg.PkgPath]; exists && (len(existing.Syntax) > 0 || len(pkg.Syntax) == 0) {
+			return false
+		}
+		i.packages[pkg.PkgPath] = pkg
+		return true
+	}, nil)
+}
+
+// syntax returns the indexed package of the path with its syntax. The files of dependencies are parsed once on
+// first use, without type-checking them.
+func (i *packageIndex) syntax(path string) (*packages.Package, error) {
+	i.mu.Lock()
+	pkg, exists := i.packages[path]
+	if !exists {
+		i.mu.Unlock()
+		return nil, fmt.Errorf("package %s is not loaded", path)
+	}
+	if len(pkg.Syntax) > 0 || len(pkg.GoFiles) == 0 {
+		i.mu.Unlock()
+		return pkg, nil
+	}
+	parsed, exists := i.parsed[path]
+	if !exists {
+		parsed = &parsedPackage{}
+		i.parsed[path] = parsed
+	}
+	i.mu.Unlock()
+
+	parsed.once.Do(func() {
+		files := make([]*ast.File, 0, len(pkg.GoFiles))
+		for _, filename := range pkg.GoFiles {
+			file, err := parser.ParseFile(i.fset, filename, nil, parser.ParseComments)
+			if err != nil {
+				parsed.err = err
+				return
+			}
+			files = append(files, file)
+		}
+		parsed.pkg = &packages.Package{ID: pkg.ID, Name: pkg.Name, PkgPath: pkg.PkgPath, GoFiles: pkg.GoFiles, Types: pkg.Types, Syntax: files}
+	})
+	return parsed.pkg, parsed.err
+}
+
+// loadTestFiles parses the _test.go files of the named Go packages and returns them by the path of the package
+// under test, i.e. the files of external test packages are returned by the path without _test suffix
+func loadTestFiles(dir string, _package ...string) (map[string][]*ast.File, error) {
+	cfg := &packages.Config{Dir: dir, Fset: token.NewFileSet(), Tests: true, Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax}
+	pkgs, err := packages.Load(cfg, _package...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]*ast.File)
+	parsed := make(map[string]struct{})
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			filename := cfg.Fset.File(file.Pos()).Name()
+			if _, exists := parsed[filename]; exists || !strings.HasSuffix(filename, "_test.go") {
+				continue
+			}
+			parsed[filename] = struct{}{}
+			path := strings.TrimSuffix(pkg.PkgPath, "_test")
+			files[path] = append(files[path], file)
+		}
+	}
+	return files, nil
+}
+
+// PackageDirs returns the sorted directories of the Go packages matched by the patterns and of their dependencies
+// whose sources can change, i.e. dependencies of the standard library and the module cache are omitted. Relative
+// patterns are resolved in dir, an empty dir denotes the current working directory.
+func PackageDirs(dir string, patterns ...string) ([]string, error) {
+	pkgs, err := loadPackageGraph(dir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return localDirs(pkgs), nil
+}
+
+// PackageDirsByPath returns the directories like PackageDirs separately for each package matched by the patterns,
+// keyed by its import path, e.g. to regenerate only the packages affected by a change
+func PackageDirsByPath(dir string, patterns ...string) (map[string][]string, error) {
+	pkgs, err := loadPackageGraph(dir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make(map[string][]string, len(pkgs))
+	for _, pkg := range pkgs {
+		dirs[pkg.PkgPath] = localDirs([]*packages.Package{pkg})
+	}
+	return dirs, nil
+}
+
+// localDirs returns the sorted directories of the packages and their dependencies within local modules
+func localDirs(pkgs []*packages.Package) []string {
+	dirs := make(map[string]struct{})
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if !isLocalModule(pkg.Module) {
+			return
+		}
+		for _, files := range [][]string{pkg.GoFiles, pkg.IgnoredFiles} {
+			for _, file := range files {
+				dirs[filepath.Dir(file)] = struct{}{}
+			}
+		}
+	})
+
+	sorted := make([]string, 0, len(dirs))
+	for d := range dirs {
+		sorted = append(sorted, d)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// packagePaths returns the import paths of the packages matched by the patterns
+func packagePaths(dir string, patterns ...string) ([]string, error) {
+	pkgs, err := packages.Load(&packages.Config{Dir: dir, Mode: packages.NeedName}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		paths = append(paths, pkg.PkgPath)
+	}
+	return paths, nil
+}
+
+// loadPackageGraph loads the named Go packages and their dependencies with their files and modules but without
+// types, which is cheap compared to loadPackages. Errors of the packages are not reported.
+func loadPackageGraph(dir string, patterns ...string) ([]*packages.Package, error) {
+	cfg := &packages.Config{Dir: dir, Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule}
+	return packages.Load(cfg, patterns...)
+}
+
+// hasErrors returns whether one of the packages or their dependencies has errors
+func hasErrors(pkgs []*packages.Package) bool {
+	failed := false
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		failed = failed || len(pkg.Errors) > 0
+	})
+
+	return failed
+}
+
+// isLocalModule returns whether the sources of the module are local, i.e. it is a main module or replaced by a
+// directory. Packages of the standard library have no module.
+func isLocalModule(module *packages.Module) bool {
+	if module == nil {
+		return false
+	}
+
+	return module.Main || (module.Replace != nil && len(module.Replace.Version) == 0)
+}
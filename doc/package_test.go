@@ -0,0 +1,84 @@
+package doc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func Test_PackageDirs(t *testing.T) {
+	dirs, err := PackageDirs(".", "./testdata")
+	assert.NoError(t, err)
+
+	testdata, err := filepath.Abs("testdata")
+	assert.NoError(t, err)
+	imported, err := filepath.Abs("../testdata")
+	assert.NoError(t, err)
+	// the standard library, e.g. time, is omitted
+	assert.Equal(t, []string{testdata, imported}, dirs)
+}
+
+func Test_PackageDirsByPath(t *testing.T) {
+	dirs, err := PackageDirsByPath(".", "../testdata", "./testdata")
+	assert.NoError(t, err)
+
+	testdata, err := filepath.Abs("testdata")
+	assert.NoError(t, err)
+	imported, err := filepath.Abs("../testdata")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"github.com/mrahbar/gostruct2openapi/doc/testdata": {testdata, imported},
+		"github.com/mrahbar/gostruct2openapi/testdata":     {imported},
+	}, dirs)
+}
+
+func Test_PackageIndex(t *testing.T) {
+	pkgs, err := loadPackages(".", "./testdata")
+	assert.NoError(t, err)
+	index := newPackageIndex()
+	index.add(pkgs...)
+
+	root, err := index.syntax("github.com/mrahbar/gostruct2openapi/doc/testdata")
+	assert.NoError(t, err)
+	assert.Same(t, pkgs[0], root)
+
+	// imports are indexed without syntax, which is parsed once on demand
+	imported := index.packages["github.com/mrahbar/gostruct2openapi/testdata"]
+	assert.NotNil(t, imported)
+	assert.Empty(t, imported.Syntax)
+	parsed, err := index.syntax("github.com/mrahbar/gostruct2openapi/testdata")
+	assert.NoError(t, err)
+	assert.Len(t, parsed.Syntax, len(imported.GoFiles))
+	again, err := index.syntax("github.com/mrahbar/gostruct2openapi/testdata")
+	assert.NoError(t, err)
+	assert.Same(t, parsed, again)
+
+	_, err = index.syntax("github.com/mrahbar/gostruct2openapi/unknown")
+	assert.Error(t, err)
+}
+
+// Benchmark_PackageSyntax compares parsing the syntax of a referenced package via the index with loading it by
+// packages.Load, which was done for every processed struct
+func Benchmark_PackageSyntax(b *testing.B) {
+	pkgs, err := loadPackages(".", "./testdata")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			index := newPackageIndex()
+			index.add(pkgs...)
+			if _, err := index.syntax("time"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("load", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := loadPackages(".", "time"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package doc
+
+import (
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc/internal"
+	"golang.org/x/tools/go/packages"
+	"sync"
+)
+
+// Session holds the state of DocumentStruct calls: the processed types, the loaded packages and their comments.
+// Every call uses a new session unless a shared session is set by WithSession. Calls sharing a session skip the
+// types generated by earlier calls, find implementations of interfaces also in the packages of earlier calls and
+// accumulate their schemas, e.g. to write the schemas of several calls into one document.
+// A session is safe for concurrent use, the calls sharing it are serialized.
+type Session struct {
+	mu               sync.Mutex
+	processedTargets map[string]struct{}
+	processedMethods map[string]struct{}
+	packages         []*packages.Package
+	index            *packageIndex
+	commentRegistry  *internal.CommentRegistry
+	schemas          SpecRegistry
+}
+
+// NewSession returns a new Session to be shared by DocumentStruct calls
+func NewSession() *Session {
+	return &Session{
+		processedTargets: make(map[string]struct{}),
+		processedMethods: make(map[string]struct{}),
+		index:            newPackageIndex(),
+		commentRegistry:  internal.NewCommentRegistry(),
+		schemas:          make(SpecRegistry),
+	}
+}
+
+// Schemas returns the schemas generated by all calls of the session sorted by ID
+func (s *Session) Schemas() []spec.Schema {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.schemas.Values()
+}
+
+// bind returns a copy of the generator using the state of the session
+func (s *Session) bind(o *openapiGenerator) *openapiGenerator {
+	bound := *o
+	bound.processedTargets = s.processedTargets
+	bound.processedMethods = s.processedMethods
+	bound.packages = s.packages
+	bound.index = s.index
+	bound.commentRegistry = s.commentRegistry
+
+	return &bound
+}
+
+// record keeps the loaded packages of the generator bound to the session and the schemas generated by it
+func (s *Session) record(bound *openapiGenerator, schemas []spec.Schema) {
+	s.packages = bound.packages
+	for _, schema := range schemas {
+		s.schemas.AddSchema(schema.ID, schema)
+	}
+}
+
+// Scope holds the package patterns loaded by DocumentStruct besides the documented packages without documenting
+// them. Like in a call documenting all of them, implementations of interfaces and examples are also found in the
+// packages of the scope, e.g. to regenerate a single package with the same schemas.
+type Scope struct {
+	Patterns []string
+	// Global is set by DocumentStruct if the schemas depend on all loaded packages, i.e. on interface implementations
+	// found in them, so they change with any package of the scope
+	Global bool
+}
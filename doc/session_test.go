@@ -0,0 +1,90 @@
+package doc
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"log"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func Test_OpenapiGenerator_RepeatedCalls(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", WithLogger(log.New(io.Discard, "", 0)))
+	generate := func() string {
+		specs, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, specs)
+		out, err := json.Marshal(specs)
+		assert.NoError(t, err)
+		return string(out)
+	}
+
+	first := generate()
+	assert.Equal(t, first, generate())
+
+	var wg sync.WaitGroup
+	concurrent := make([]string, 4)
+	for i := range concurrent {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			concurrent[i] = generate()
+		}(i)
+	}
+	wg.Wait()
+	for _, out := range concurrent {
+		assert.Equal(t, first, out)
+	}
+}
+
+func Test_Session(t *testing.T) {
+	logger := WithLogger(log.New(io.Discard, "", 0))
+	all, err := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", logger).
+		DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata", "github.com/mrahbar/gostruct2openapi/testdata")
+	assert.NoError(t, err)
+
+	session := NewSession()
+	generator := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", logger, WithSession(session))
+	first, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first)
+	second, err := generator.DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata", "github.com/mrahbar/gostruct2openapi/testdata")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, second)
+	assert.Len(t, session.Schemas(), len(first)+len(second))
+	for _, schema := range second {
+		for _, previous := range first {
+			assert.NotEqual(t, previous.ID, schema.ID)
+		}
+	}
+
+	expected, err := json.Marshal(all)
+	assert.NoError(t, err)
+	actual, err := json.Marshal(session.Schemas())
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func Test_Scope(t *testing.T) {
+	logger := WithLogger(log.New(io.Discard, "", 0))
+	documented, err := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", logger).
+		DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
+	assert.NoError(t, err)
+
+	// the packages of the scope are loaded but not documented
+	scope := &Scope{Patterns: []string{"github.com/mrahbar/gostruct2openapi/testdata"}}
+	scoped, err := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", logger, WithScope(scope)).
+		DocumentStruct("github.com/mrahbar/gostruct2openapi/doc/testdata")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaIDs(documented), schemaIDs(scoped))
+	// the implementations of TestShape are looked up in all loaded packages
+	assert.True(t, scope.Global)
+
+	scope = &Scope{Patterns: []string{"github.com/mrahbar/gostruct2openapi/doc/testdata"}}
+	_, err = NewOpenapiGenerator(regexp.MustCompile("^TestOtherBaseStruct$"), "json", logger, WithScope(scope)).
+		DocumentStruct("github.com/mrahbar/gostruct2openapi/testdata")
+	assert.NoError(t, err)
+	assert.False(t, scope.Global)
+}
@@ -3,6 +3,9 @@ package testdata
 import (
 	"fmt"
 	"github.com/mrahbar/gostruct2openapi/testdata"
+	"math/big"
+	"net"
+	"net/netip"
 	"time"
 )
 
@@ -132,3 +135,206 @@ func (g *httpHandler) handleRequest() {
 	var resp2 httpHandlerResp
 	fmt.Println(resp2)
 }
+
+// TestShape is implemented by all test shapes
+type TestShape interface {
+	Area() float64
+}
+
+// @title Test Circle
+// Test Circle description
+type TestCircle struct {
+	//Kind comment
+	Kind string `json:"kind" openapi:"const=circle"`
+	//Radius comment
+	Radius float64 `json:"radius"`
+}
+
+func (c TestCircle) Area() float64 {
+	return 3.14 * c.Radius * c.Radius
+}
+
+// @title Test Square
+// Test Square description
+type TestSquare struct {
+	//Kind comment
+	Kind string `json:"kind" openapi:"const=square"`
+	//Length comment
+	Length float64 `json:"length"`
+}
+
+func (s *TestSquare) Area() float64 {
+	return s.Length * s.Length
+}
+
+// TestAnnotatedInterface is an empty interface with explicitly listed implementations
+// @implementations TestCircle, TestUnderlyingStruct
+type TestAnnotatedInterface interface {
+}
+
+// @title Test Polymorphic Struct
+// Test Polymorphic Struct description
+type TestPolymorphicStruct struct {
+	//FieldA comment
+	FieldA TestShape
+	//FieldB comment
+	FieldB []TestShape
+	//FieldC comment
+	FieldC TestAnnotatedInterface
+}
+
+// @title Test Item
+// Test Item description
+type TestItem struct {
+	//ID comment
+	//@readOnly
+	ID string `json:"id" openapi:"required"`
+	//Name comment
+	Name string `json:"name" openapi:"required"`
+	//Secret comment
+	//@writeOnly
+	Secret string `json:"secret"`
+	//Owner comment
+	Owner TestUnderlyingStruct `json:"owner" openapi:"readOnly"`
+}
+
+// TestMoney is rendered as string by a custom type mapping
+type TestMoney struct {
+	Amount   int64
+	Currency string
+}
+
+// @title Test Type Mapping Struct
+// Test Type Mapping Struct description
+type TestTypeMappingStruct struct {
+	//FieldA comment
+	FieldA time.Duration
+	//FieldB comment
+	FieldB net.IP
+	//FieldC comment
+	FieldC *big.Int
+	//FieldD comment
+	FieldD []netip.Addr
+	//FieldE comment
+	FieldE map[string]time.Time
+	//FieldF comment
+	FieldF TestMoney
+	//FieldG comment
+	FieldG []byte
+	//FieldH comment
+	FieldH int64
+}
+
+// @title Test Example Struct
+// Test Example Struct description
+// @example {"name": "Widget", "size": 3}
+type TestExampleStruct struct {
+	//Name comment
+	//@example Widget
+	Name string `json:"name"`
+	//Size comment
+	//@example 3
+	Size int `json:"size"`
+	//Created comment
+	Created time.Time `json:"created"`
+	//Owner comment
+	//@example {"UnderlyingFieldB": "owner"}
+	Owner *TestUnderlyingStruct `json:"owner"`
+	//Children comment
+	Children []TestExampleNode `json:"children"`
+}
+
+// TestExampleNode is a recursive struct without examples
+type TestExampleNode struct {
+	//Name comment
+	Name string `json:"name"`
+	//Children comment
+	Children []TestExampleNode `json:"children"`
+	//Parent comment
+	Parent *TestExampleNode `json:"parent"`
+}
+
+// TestLiteralOrder has its example given by the variable TestLiteralOrderExample
+type TestLiteralOrder struct {
+	TestLiteralAudit
+	//ID comment
+	ID string `json:"id"`
+	//Items comment
+	Items []TestLiteralItem `json:"items"`
+	//Total comment
+	Total int `json:"total"`
+	//Currency comment
+	Currency string `json:"currency,omitempty"`
+	//Note comment
+	Note string `json:"note,omitempty"`
+	//Created comment
+	Created time.Time `json:"created"`
+	//Timeout comment
+	Timeout time.Duration `json:"timeout"`
+	//Labels comment
+	Labels map[string]string `json:"labels"`
+	//Weight comment
+	Weight float32 `json:"weight"`
+	//Parent comment
+	Parent *TestLiteralOrder `json:"parent"`
+}
+
+// TestLiteralAudit is embedded into TestLiteralOrder
+type TestLiteralAudit struct {
+	//CreatedBy comment
+	CreatedBy string `json:"createdBy"`
+}
+
+// TestLiteralRevision embeds TestLiteralAudit by a tag name
+type TestLiteralRevision struct {
+	TestLiteralAudit `json:"audit"`
+	//Revision comment
+	Revision int `json:"revision"`
+}
+
+// TestLiteralItem is an item of TestLiteralOrder
+type TestLiteralItem struct {
+	//SKU comment
+	SKU string `json:"sku"`
+	//Quantity comment
+	Quantity int `json:"quantity"`
+	//Price comment
+	Price float64 `json:"price,omitempty"`
+}
+
+const testLiteralCurrency = "EUR"
+
+var testLiteralItem = TestLiteralItem{SKU: "sku-1", Quantity: 2}
+
+// TestLiteralOrderExample is the example of TestLiteralOrder
+// @example TestLiteralOrder
+var TestLiteralOrderExample = &TestLiteralOrder{
+	TestLiteralAudit: TestLiteralAudit{CreatedBy: "admin"},
+	ID:               "o-1",
+	Items:            []TestLiteralItem{testLiteralItem, {SKU: "sku-2", Quantity: 1, Price: -1.5}},
+	Total:            10 * 2,
+	Currency:         testLiteralCurrency,
+	Created:          time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC),
+	Labels:           map[string]string{"channel": "web"},
+	Weight:           1.1,
+}
+
+// TestLiteralRevisionExample is the example of TestLiteralRevision
+// @example TestLiteralRevision
+var TestLiteralRevisionExample = TestLiteralRevision{TestLiteralAudit: TestLiteralAudit{CreatedBy: "admin"}, Revision: 2}
+
+// testLiteralItemExample cannot be evaluated and is skipped
+// @example TestLiteralItem
+var testLiteralItemExample = newTestLiteralItem()
+
+func newTestLiteralItem() TestLiteralItem {
+	return TestLiteralItem{SKU: "sku-3"}
+}
+
+// TestOutputStruct has its example given by the output of the function ExampleTestOutputStruct
+type TestOutputStruct struct {
+	//Name comment
+	Name string `json:"name"`
+	//Tags comment
+	Tags []string `json:"tags"`
+}
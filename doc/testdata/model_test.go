@@ -0,0 +1,30 @@
+package testdata_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc/testdata"
+)
+
+func ExampleTestOutputStruct() {
+	bytes, _ := json.Marshal(testdata.TestOutputStruct{Name: "Widget", Tags: []string{"new"}})
+	fmt.Println(string(bytes))
+	// Output: {"name":"Widget","tags":["new"]}
+}
+
+func ExampleTestOutputStruct_empty() {
+	bytes, _ := json.Marshal(testdata.TestOutputStruct{})
+	fmt.Println(string(bytes))
+	// Output: {"name":"","tags":null}
+}
+
+func ExampleTestLiteralItem() {
+	fmt.Println(testdata.TestLiteralItem{SKU: "sku-1"}.SKU)
+	// Output: sku-1
+}
+
+func ExampleTestLiteralAudit() {
+	bytes, _ := json.Marshal(testdata.TestLiteralAudit{CreatedBy: "admin"}.CreatedBy)
+	fmt.Println(string(bytes))
+	// Output: "admin"
+}
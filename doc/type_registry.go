@@ -0,0 +1,82 @@
+package doc
+
+import (
+	"github.com/mrahbar/gostruct2openapi/doc/internal"
+	"go/types"
+)
+
+// TypeMapping describes the fixed schema a Go type is rendered as
+type TypeMapping struct {
+	Type    string      `json:"type" yaml:"type"`
+	Format  string      `json:"format,omitempty" yaml:"format,omitempty"`
+	Pattern string      `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Example interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+func (t TypeMapping) toSpecField() *internal.SpecField {
+	sf := internal.NewSpecFieldWithFormat(internal.SpecType(t.Type), t.Format)
+	sf.SetPattern(t.Pattern)
+	sf.SetExample(t.Example)
+	return sf
+}
+
+// builtinTypeMappings holds the mappings of well-known types whose JSON encoding differs from their Go structure.
+// Note that net/url.URL is not contained since it has no text marshaller and is therefore encoded as object.
+var builtinTypeMappings = map[string]TypeMapping{
+	"time.Time":                             {Type: internal.StringType.String(), Format: internal.TimeFormat},
+	"time.Duration":                         {Type: internal.IntegerType.String(), Format: "int64", Example: 1000000000},
+	"net.IP":                                {Type: internal.StringType.String(), Example: "192.0.2.1"},
+	"net/netip.Addr":                        {Type: internal.StringType.String(), Example: "192.0.2.1"},
+	"net/netip.AddrPort":                    {Type: internal.StringType.String(), Example: "192.0.2.1:8080"},
+	"net/netip.Prefix":                      {Type: internal.StringType.String(), Example: "192.0.2.0/24"},
+	"math/big.Int":                          {Type: internal.IntegerType.String()},
+	"math/big.Float":                        {Type: internal.StringType.String(), Pattern: `^-?\d+(\.\d+)?([eE][+-]?\d+)?$`},
+	"encoding/json.Number":                  {Type: internal.NumberType.String()},
+	"github.com/google/uuid.UUID":           {Type: internal.StringType.String(), Format: "uuid", Example: "3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+	"github.com/gofrs/uuid.UUID":            {Type: internal.StringType.String(), Format: "uuid", Example: "3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+	"github.com/shopspring/decimal.Decimal": {Type: internal.StringType.String(), Pattern: `^-?\d+(\.\d+)?$`, Example: "12.34"},
+}
+
+// TypeRegistry maps fully qualified Go types, e.g. github.com/google/uuid.UUID, to fixed schemas.
+// A new registry contains mappings for well-known standard library and third-party types.
+type TypeRegistry struct {
+	mappings map[string]TypeMapping
+}
+
+// NewTypeRegistry returns a new TypeRegistry containing the built-in mappings
+func NewTypeRegistry() *TypeRegistry {
+	registry := &TypeRegistry{mappings: make(map[string]TypeMapping)}
+	for goType, mapping := range builtinTypeMappings {
+		registry.Register(goType, mapping)
+	}
+
+	return registry
+}
+
+// Register maps the fully qualified Go type to the given schema, overriding an existing mapping
+func (t *TypeRegistry) Register(goType string, mapping TypeMapping) {
+	t.mappings[goType] = mapping
+}
+
+// Lookup returns the mapping of the fully qualified Go type
+func (t *TypeRegistry) Lookup(goType string) (TypeMapping, bool) {
+	mapping, exists := t.mappings[goType]
+	return mapping, exists
+}
+
+// lookupType returns the spec field of the given type if the type or the type it points to is registered
+func (t *TypeRegistry) lookupType(typ types.Type) *internal.SpecField {
+	switch u := typ.(type) {
+	case *types.Pointer:
+		return t.lookupType(u.Elem())
+	case *types.Named:
+		if u.Obj().Pkg() == nil {
+			return nil
+		}
+		if mapping, exists := t.Lookup(u.Obj().Pkg().Path() + "." + u.Obj().Name()); exists {
+			return mapping.toSpecField()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,386 @@
+package doc
+
+import (
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity is the severity of a Diagnostic
+type Severity string
+
+const (
+	// SeverityError marks an invalid document
+	SeverityError Severity = "error"
+	// SeverityWarning marks a valid document which likely does not behave as intended
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is an issue found by the validation of schemas or a document
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// Schema is the name of the component, empty for issues outside of the components
+	Schema string `json:"schema,omitempty"`
+	// Path is the JSON pointer of the issue within the component or, without schema, within the document
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String returns the diagnostic as a line of a report
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s%s: %s", d.Severity, d.Schema, d.Path, d.Message)
+}
+
+// HasErrors returns whether one of the diagnostics is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// componentNamePattern is the pattern of the keys of the components of an OpenAPI document
+var componentNamePattern = regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+
+// schemaKeywords are the keywords of the schema object of OpenAPI 3.0
+var schemaKeywords = map[string]struct{}{
+	"title": {}, "multipleOf": {}, "maximum": {}, "exclusiveMaximum": {}, "minimum": {}, "exclusiveMinimum": {},
+	"maxLength": {}, "minLength": {}, "pattern": {}, "maxItems": {}, "minItems": {}, "uniqueItems": {},
+	"maxProperties": {}, "minProperties": {}, "required": {}, "enum": {}, "type": {}, "allOf": {}, "oneOf": {},
+	"anyOf": {}, "not": {}, "items": {}, "properties": {}, "additionalProperties": {}, "description": {},
+	"format": {}, "default": {}, "nullable": {}, "discriminator": {}, "readOnly": {}, "writeOnly": {}, "xml": {},
+	"externalDocs": {}, "example": {}, "deprecated": {}, "$ref": {},
+}
+
+// schemaKeywords31 are the keywords added by the JSON Schema 2020-12 dialect of OpenAPI 3.1
+var schemaKeywords31 = map[string]struct{}{
+	"$schema": {}, "$id": {}, "$anchor": {}, "$defs": {}, "$comment": {}, "$dynamicRef": {}, "$dynamicAnchor": {},
+	"const": {}, "examples": {}, "prefixItems": {}, "contains": {}, "minContains": {}, "maxContains": {},
+	"if": {}, "then": {}, "else": {}, "dependentRequired": {}, "dependentSchemas": {}, "patternProperties": {},
+	"propertyNames": {}, "unevaluatedItems": {}, "unevaluatedProperties": {}, "contentEncoding": {},
+	"contentMediaType": {}, "contentSchema": {},
+}
+
+var schemaTypes = map[string]struct{}{
+	"string": {}, "number": {}, "integer": {}, "boolean": {}, "array": {}, "object": {},
+}
+
+// ValidateSchemas validates the schemas as components of an OpenAPI 3.0 document keyed by their ComponentName.
+// The schemas are checked against the rules of the schema object and each reference must resolve to one of them.
+func ValidateSchemas(schemas []spec.Schema) []Diagnostic {
+	components, err := schemaValues(schemas)
+	if err != nil {
+		return []Diagnostic{{Severity: SeverityError, Message: err.Error()}}
+	}
+
+	v := &validator{components: components, referenced: make(map[string]struct{})}
+	v.validateComponents()
+	return v.sorted()
+}
+
+// ValidateDocument validates the YAML or JSON OpenAPI 3.0 or 3.1 document. Besides the rules of the schema
+// object every local reference must resolve. If the document has paths, component schemas which are never
+// referenced are reported as warnings.
+func ValidateDocument(content []byte) ([]Diagnostic, error) {
+	root, err := parseNode(content)
+	if err != nil {
+		return nil, err
+	}
+	value, err := fromNode(root)
+	if err != nil {
+		return nil, err
+	}
+	document := jsonschema.AsObject(value)
+
+	v := &validator{components: make(map[string]map[string]interface{}), referenced: make(map[string]struct{})}
+	version, _ := document["openapi"].(string)
+	switch {
+	case strings.HasPrefix(version, "3.0."):
+	case strings.HasPrefix(version, "3.1."):
+		v.openapi31 = true
+	default:
+		v.add(SeverityError, "/openapi", "unsupported OpenAPI version %s, expected 3.0.x or 3.1.x", valueOrNone(document["openapi"]))
+	}
+
+	info := jsonschema.AsObject(document["info"])
+	for _, field := range []string{"title", "version"} {
+		if _, ok := info[field].(string); !ok {
+			v.add(SeverityError, "/info/"+field, "info %s is required", field)
+		}
+	}
+	paths, hasPaths := document["paths"]
+	if !hasPaths && !v.openapi31 {
+		v.add(SeverityError, "/paths", "paths are required")
+	}
+
+	for name, schema := range jsonschema.AsObject(jsonschema.AsObject(document["components"])["schemas"]) {
+		v.components[name] = jsonschema.AsObject(schema)
+	}
+	v.validateComponents()
+
+	// references outside of the component schemas, e.g. of operations
+	for key, child := range document {
+		if key != "components" {
+			v.collectRefs("/"+jsonschema.EscapePointer(key), child)
+		}
+	}
+	for key, child := range jsonschema.AsObject(document["components"]) {
+		if key != "schemas" {
+			v.collectRefs("/components/"+jsonschema.EscapePointer(key), child)
+		}
+	}
+
+	if len(jsonschema.AsObject(paths)) > 0 {
+		var names []string
+		for name := range v.components {
+			if _, exists := v.referenced[name]; !exists {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v.schema = name
+			v.add(SeverityWarning, "", "component is not referenced")
+		}
+	}
+
+	return v.sorted(), nil
+}
+
+type validator struct {
+	components  map[string]map[string]interface{}
+	referenced  map[string]struct{}
+	openapi31   bool
+	schema      string
+	diagnostics []Diagnostic
+}
+
+func (v *validator) add(severity Severity, path string, message string, args ...interface{}) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{Severity: severity, Schema: v.schema, Path: path, Message: fmt.Sprintf(message, args...)})
+}
+
+// sorted returns the diagnostics ordered by component and path
+func (v *validator) sorted() []Diagnostic {
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i], v.diagnostics[j]
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		return a.Path < b.Path
+	})
+
+	return v.diagnostics
+}
+
+func (v *validator) validateComponents() {
+	var names []string
+	for name := range v.components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v.schema = name
+		if !componentNamePattern.MatchString(name) {
+			v.add(SeverityError, "", "component name %q does not match %s", name, componentNamePattern)
+		}
+		v.validateSchema("", v.components[name])
+	}
+	v.schema = ""
+}
+
+// validateSchema validates the schema object at the given path of the current component
+func (v *validator) validateSchema(path string, schema map[string]interface{}) {
+	var keys []string
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		_, known := schemaKeywords[key]
+		if _, known31 := schemaKeywords31[key]; known31 && v.openapi31 {
+			known = true
+		}
+		if !known && !strings.HasPrefix(key, "x-") {
+			v.add(SeverityError, path+"/"+jsonschema.EscapePointer(key), "%s is no keyword of an OpenAPI schema", key)
+		}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		v.validateRef(path+"/$ref", ref)
+		if !v.openapi31 {
+			for _, key := range keys {
+				if key != "$ref" && !strings.HasPrefix(key, "x-") {
+					v.add(SeverityWarning, path+"/"+jsonschema.EscapePointer(key), "%s is ignored next to $ref, wrap the reference in allOf", key)
+				}
+			}
+		}
+	}
+
+	v.validateType(path, schema)
+
+	properties := jsonschema.AsObject(schema["properties"])
+	required := jsonschema.AsArray(schema["required"])
+	if _, exists := schema["required"]; exists && len(required) == 0 && !v.openapi31 {
+		v.add(SeverityError, path+"/required", "required must not be empty")
+	}
+	seen := make(map[string]struct{})
+	for i, name := range required {
+		s, ok := name.(string)
+		if !ok {
+			v.add(SeverityError, fmt.Sprintf("%s/required/%d", path, i), "required entries must be strings")
+			continue
+		}
+		if _, duplicate := seen[s]; duplicate {
+			v.add(SeverityError, fmt.Sprintf("%s/required/%d", path, i), "property %s is required twice", s)
+		}
+		seen[s] = struct{}{}
+		if _, defined := properties[s]; !defined && properties != nil && len(jsonschema.AsArray(schema["allOf"])) == 0 {
+			v.add(SeverityWarning, fmt.Sprintf("%s/required/%d", path, i), "required property %s is not defined", s)
+		}
+	}
+
+	if enum, exists := schema["enum"]; exists && len(jsonschema.AsArray(enum)) == 0 {
+		v.add(SeverityError, path+"/enum", "enum must not be empty")
+	}
+	for _, pair := range [][2]string{{"minimum", "maximum"}, {"minLength", "maxLength"}, {"minItems", "maxItems"}, {"minProperties", "maxProperties"}} {
+		min, hasMin := schema[pair[0]].(float64)
+		max, hasMax := schema[pair[1]].(float64)
+		if hasMin && hasMax && min > max {
+			v.add(SeverityError, path+"/"+pair[0], "%s %v is greater than %s %v", pair[0], min, pair[1], max)
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.add(SeverityWarning, path+"/pattern", "pattern is no valid regular expression: %v", err)
+		}
+	}
+	if schema["readOnly"] == true && schema["writeOnly"] == true {
+		v.add(SeverityError, path, "schema must not be both readOnly and writeOnly")
+	}
+	if discriminator, exists := schema["discriminator"]; exists {
+		if _, ok := jsonschema.AsObject(discriminator)["propertyName"].(string); !ok {
+			v.add(SeverityError, path+"/discriminator", "discriminator requires a propertyName")
+		}
+		for value, ref := range jsonschema.AsObject(jsonschema.AsObject(discriminator)["mapping"]) {
+			if s, ok := ref.(string); ok && strings.HasPrefix(s, "#") {
+				v.validateRef(path+"/discriminator/mapping/"+jsonschema.EscapePointer(value), s)
+			}
+		}
+	}
+
+	for name, property := range properties {
+		v.validateSubschema(path+"/properties/"+jsonschema.EscapePointer(name), property)
+	}
+	if items, exists := schema["items"]; exists {
+		if _, isArray := items.([]interface{}); isArray {
+			v.add(SeverityError, path+"/items", "items must be a schema")
+		} else {
+			v.validateSubschema(path+"/items", items)
+		}
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+		v.validateSchema(path+"/additionalProperties", additional)
+	}
+	if not, exists := schema["not"]; exists {
+		v.validateSubschema(path+"/not", not)
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf", "prefixItems"} {
+		if subschemas, exists := schema[key]; exists {
+			if len(jsonschema.AsArray(subschemas)) == 0 {
+				v.add(SeverityError, path+"/"+key, "%s must be a non-empty array", key)
+			}
+			for i, subschema := range jsonschema.AsArray(subschemas) {
+				v.validateSubschema(fmt.Sprintf("%s/%s/%d", path, key, i), subschema)
+			}
+		}
+	}
+}
+
+func (v *validator) validateSubschema(path string, schema interface{}) {
+	if v.openapi31 {
+		if _, isBool := schema.(bool); isBool {
+			return
+		}
+	}
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		v.add(SeverityError, path, "schema must be an object")
+		return
+	}
+	v.validateSchema(path, object)
+}
+
+func (v *validator) validateType(path string, schema map[string]interface{}) {
+	var types []interface{}
+	switch t := schema["type"].(type) {
+	case nil:
+		return
+	case string:
+		types = []interface{}{t}
+	case []interface{}:
+		if !v.openapi31 {
+			v.add(SeverityError, path+"/type", "type must be a string in OpenAPI 3.0, use nullable for null values")
+			return
+		}
+		types = t
+	default:
+		v.add(SeverityError, path+"/type", "type must be a string")
+		return
+	}
+
+	for _, t := range types {
+		name, _ := t.(string)
+		if _, valid := schemaTypes[name]; !valid && !(v.openapi31 && name == "null") {
+			v.add(SeverityError, path+"/type", "unknown type %s", valueOrNone(t))
+		}
+		if name == "array" && !v.openapi31 {
+			if _, hasItems := schema["items"]; !hasItems {
+				v.add(SeverityError, path+"/items", "items are required for type array")
+			}
+		}
+	}
+}
+
+// validateRef checks that a local reference resolves to a component schema
+func (v *validator) validateRef(path, ref string) {
+	if !strings.HasPrefix(ref, "#") {
+		// references to other documents cannot be resolved
+		return
+	}
+	if !strings.HasPrefix(ref, jsonschema.ComponentRefPrefix) {
+		v.add(SeverityError, path, "reference %s does not point to a component schema", ref)
+		return
+	}
+
+	name := jsonschema.UnescapePointer(strings.TrimPrefix(ref, jsonschema.ComponentRefPrefix))
+	v.referenced[name] = struct{}{}
+	if _, exists := v.components[name]; !exists {
+		v.add(SeverityError, path, "reference %s does not resolve, no component schema %s", ref, name)
+	}
+}
+
+// collectRefs validates all references to component schemas outside of the component schemas
+func (v *validator) collectRefs(path string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if ref, ok := child.(string); ok && key == "$ref" && strings.HasPrefix(ref, jsonschema.ComponentRefPrefix) {
+				v.validateRef(path+"/$ref", ref)
+				continue
+			}
+			v.collectRefs(path+"/"+jsonschema.EscapePointer(key), child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			v.collectRefs(fmt.Sprintf("%s/%d", path, i), child)
+		}
+	}
+}
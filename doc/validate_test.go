@@ -0,0 +1,96 @@
+package doc
+
+import (
+	"bytes"
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"regexp"
+	"testing"
+)
+
+func diagnosticLines(diagnostics []Diagnostic) []string {
+	var lines []string
+	for _, diagnostic := range diagnostics {
+		lines = append(lines, diagnostic.String())
+	}
+	return lines
+}
+
+func Test_ValidateSchemas(t *testing.T) {
+	item := *spec.MapProperty(nil)
+	item.ID = "Item"
+	item.Properties = map[string]spec.Schema{"id": *spec.StringProperty().WithMinLength(5).WithMaxLength(1)}
+	item.Required = []string{"name", "name"}
+
+	list := *spec.ArrayProperty(nil)
+	list.ID = "Item List"
+	list.Properties = map[string]spec.Schema{"owner": *spec.RefSchema("#/components/schemas/Owner")}
+
+	diagnostics := ValidateSchemas([]spec.Schema{item, list})
+	assert.True(t, HasErrors(diagnostics))
+	assert.Equal(t, []string{
+		"error: Item/properties/id/minLength: minLength 5 is greater than maxLength 1",
+		"warning: Item/required/0: required property name is not defined",
+		"error: Item/required/1: property name is required twice",
+		"warning: Item/required/1: required property name is not defined",
+		"error: Item List: component name \"Item List\" does not match ^[a-zA-Z0-9.\\-_]+$",
+		"error: Item List/items: items are required for type array",
+		"error: Item List/properties/owner/$ref: reference #/components/schemas/Owner does not resolve, no component schema Owner",
+	}, diagnosticLines(diagnostics))
+}
+
+func Test_ValidateSchemas_Generated(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithSchemaVariants(CreateVariant, PatchVariant)},
+		{WithGoTypeExtension(), WithSchemaVariants(CreateVariant, PatchVariant)},
+	} {
+		var logs bytes.Buffer
+		generator := NewOpenapiGenerator(regexp.MustCompile(".*"), "json", append(opts, WithLogger(log.New(&logs, "", 0)))...)
+		_, err := generator.DocumentStruct("./testdata")
+		assert.NoError(t, err)
+		assert.Empty(t, diagnosticLines(generator.Diagnostics()))
+		assert.NotContains(t, logs.String(), "error:")
+	}
+
+}
+
+func Test_ValidateDocument(t *testing.T) {
+	document := `openapi: 3.0.3
+info:
+  title: Test
+paths:
+  /items:
+    get:
+      responses:
+        "200":
+          description: Items
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+components:
+  schemas:
+    Item:
+      type: object
+      id: Item
+      properties:
+        kind:
+          type: [string, "null"]
+        size:
+          type: integer
+          minimum: 10
+          maximum: 1
+    Unused:
+      type: string
+`
+	diagnostics, err := ValidateDocument([]byte(document))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"error: /info/version: info version is required",
+		"error: Item/id: id is no keyword of an OpenAPI schema",
+		"error: Item/properties/kind/type: type must be a string in OpenAPI 3.0, use nullable for null values",
+		"error: Item/properties/size/minimum: minimum 10 is greater than maximum 1",
+		"warning: Unused: component is not referenced",
+	}, diagnosticLines(diagnostics))
+}
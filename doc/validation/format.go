@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// builtinFormats checks the string formats emitted by the generator and the common formats of OpenAPI.
+// Unknown formats are not validated.
+var builtinFormats = map[string]func(value string) error{
+	// RFC3339 is the format the generator emits for time.Time
+	"RFC3339":   checkDateTime,
+	"date-time": checkDateTime,
+	"date": func(value string) error {
+		_, err := time.Parse("2006-01-02", value)
+		return err
+	},
+	"byte": func(value string) error {
+		_, err := base64.StdEncoding.DecodeString(value)
+		return err
+	},
+	"uuid": func(value string) error {
+		if !uuidPattern.MatchString(value) {
+			return errors.New("expected 8-4-4-4-12 hexadecimal digits")
+		}
+		return nil
+	},
+	"ip": func(value string) error {
+		if net.ParseIP(value) == nil {
+			return errors.New("expected an IPv4 or IPv6 address")
+		}
+		return nil
+	},
+	"ipv4": func(value string) error {
+		if ip := net.ParseIP(value); ip == nil || strings.Contains(value, ":") {
+			return errors.New("expected an IPv4 address")
+		}
+		return nil
+	},
+	"ipv6": func(value string) error {
+		if ip := net.ParseIP(value); ip == nil || !strings.Contains(value, ":") {
+			return errors.New("expected an IPv6 address")
+		}
+		return nil
+	},
+	"cidr": func(value string) error {
+		_, _, err := net.ParseCIDR(value)
+		return err
+	},
+	"email": func(value string) error {
+		_, err := mail.ParseAddress(value)
+		return err
+	},
+	"uri": func(value string) error {
+		u, err := url.Parse(value)
+		if err == nil && !u.IsAbs() {
+			return errors.New("expected an absolute URI")
+		}
+		return err
+	},
+}
+
+func checkDateTime(value string) error {
+	_, err := time.Parse(time.RFC3339, value)
+	return err
+}
@@ -0,0 +1,579 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the media type of problem details responses
+const ProblemContentType = "application/problem+json"
+
+// operationMethods are the methods of the operations of a path item in the order of the OpenAPI specification
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Problem is a problem details response of RFC 7807 listing the violations of the document
+type Problem struct {
+	Type   string      `json:"type"`
+	Title  string      `json:"title"`
+	Status int         `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+	Errors []Violation `json:"errors,omitempty"`
+}
+
+// Violation is a part of a request or response which does not match the document
+type Violation struct {
+	// In is the location of the violation: path, query, header, cookie, body or status
+	In string `json:"in"`
+	// Name is the name of the parameter
+	Name string `json:"name,omitempty"`
+	// Path is the JSON pointer of the violation within the parameter or body
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// MiddlewareOption configures optional behaviour of the Middleware
+type MiddlewareOption func(m *Middleware)
+
+// WithResponseValidation validates the responses of the handler, a response violating the document is replaced by
+// a problem with status 500. As the response is buffered this is meant for tests and development.
+func WithResponseValidation() MiddlewareOption {
+	return func(m *Middleware) {
+		m.validateResponses = true
+	}
+}
+
+// WithStrictRouting rejects requests which match no operation of the document with status 404 or 405,
+// by default they are passed to the handler unvalidated
+func WithStrictRouting() MiddlewareOption {
+	return func(m *Middleware) {
+		m.strictRouting = true
+	}
+}
+
+// WithBasePath sets the prefix of the request paths which is not part of the paths of the document, e.g. /api/v1
+func WithBasePath(prefix string) MiddlewareOption {
+	return func(m *Middleware) {
+		m.basePath = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// WithValidatorOptions configures the Validator of the payloads, e.g. WithNullForOptional()
+func WithValidatorOptions(opts ...Option) MiddlewareOption {
+	return func(m *Middleware) {
+		m.validatorOptions = append(m.validatorOptions, opts...)
+	}
+}
+
+// Middleware validates requests and optionally responses of an http.Handler against the operations of a document.
+// The generator emits no paths, so the operations are read from the paths of a hand-written document, e.g. one the
+// generated schemas were merged into. Path, query, header and cookie parameters as well as JSON bodies are validated.
+type Middleware struct {
+	validator         *Validator
+	components        map[string]interface{}
+	operations        []*operation
+	validateResponses bool
+	strictRouting     bool
+	basePath          string
+	validatorOptions  []Option
+}
+
+// operation is an operation of the document with resolved parameters, request body and responses
+type operation struct {
+	method     string
+	path       string
+	segments   []string
+	parameters []parameter
+	body       map[string]interface{}
+	responses  map[string]interface{}
+}
+
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	explode  bool
+	schema   map[string]interface{}
+}
+
+// NewMiddleware returns a middleware validating against the operations of the YAML or JSON OpenAPI document
+func NewMiddleware(content []byte, opts ...MiddlewareOption) (*Middleware, error) {
+	document, err := parseDocument(content)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Middleware{components: jsonschema.AsObject(document["components"])}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.validator = newValidator(m.validatorOptions)
+	for name, schema := range jsonschema.AsObject(m.components["schemas"]) {
+		m.validator.schemas[name] = jsonschema.AsObject(schema)
+	}
+
+	paths := jsonschema.AsObject(document["paths"])
+	templates := make([]string, 0, len(paths))
+	for template := range paths {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	for _, template := range templates {
+		item, err := m.resolveComponent(jsonschema.AsObject(paths[template]), "pathItems")
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", template, err)
+		}
+		for _, method := range operationMethods {
+			definition, exists := item[method].(map[string]interface{})
+			if !exists {
+				continue
+			}
+			op, err := m.newOperation(method, template, item, definition)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), template, err)
+			}
+			m.operations = append(m.operations, op)
+		}
+	}
+
+	// concrete paths take precedence over templated paths, e.g. /items/mine over /items/{id}
+	sort.SliceStable(m.operations, func(i, j int) bool {
+		return templatedSegments(m.operations[i].segments) < templatedSegments(m.operations[j].segments)
+	})
+
+	return m, nil
+}
+
+func (m *Middleware) newOperation(method, template string, item, definition map[string]interface{}) (*operation, error) {
+	op := &operation{method: strings.ToUpper(method), path: template, segments: splitPath(template)}
+
+	// parameters of the operation override the parameters of the path item with the same name and location
+	byKey := make(map[string]int)
+	for _, list := range []interface{}{item["parameters"], definition["parameters"]} {
+		for _, entry := range jsonschema.AsArray(list) {
+			definition, err := m.resolveComponent(jsonschema.AsObject(entry), "parameters")
+			if err != nil {
+				return nil, err
+			}
+			p := parameter{schema: jsonschema.AsObject(definition["schema"])}
+			p.name, _ = definition["name"].(string)
+			p.in, _ = definition["in"].(string)
+			p.required, _ = definition["required"].(bool)
+			p.explode = p.in == "query" || p.in == "cookie"
+			if explode, ok := definition["explode"].(bool); ok {
+				p.explode = explode
+			}
+
+			if i, exists := byKey[p.in+":"+p.name]; exists {
+				op.parameters[i] = p
+			} else {
+				byKey[p.in+":"+p.name] = len(op.parameters)
+				op.parameters = append(op.parameters, p)
+			}
+		}
+	}
+
+	if body, ok := definition["requestBody"].(map[string]interface{}); ok {
+		resolved, err := m.resolveComponent(body, "requestBodies")
+		if err != nil {
+			return nil, err
+		}
+		op.body = resolved
+	}
+
+	op.responses = make(map[string]interface{})
+	for status, response := range jsonschema.AsObject(definition["responses"]) {
+		resolved, err := m.resolveComponent(jsonschema.AsObject(response), "responses")
+		if err != nil {
+			return nil, err
+		}
+		op.responses[strings.ToUpper(status)] = resolved
+	}
+
+	return op, nil
+}
+
+// resolveComponent resolves a reference to a component of the given kind, e.g. #/components/parameters/Limit
+func (m *Middleware) resolveComponent(value map[string]interface{}, kind string) (map[string]interface{}, error) {
+	ref, ok := value["$ref"].(string)
+	if !ok {
+		return value, nil
+	}
+
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return nil, fmt.Errorf("reference %s does not point to %s", ref, kind)
+	}
+	resolved, exists := jsonschema.AsObject(m.components[kind])[jsonschema.UnescapePointer(strings.TrimPrefix(ref, prefix))].(map[string]interface{})
+	if !exists {
+		return nil, fmt.Errorf("reference %s does not resolve", ref)
+	}
+
+	return m.resolveComponent(resolved, kind)
+}
+
+// Handler returns the handler validating the requests before passing them to next
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, pathParams, allowed := m.match(r)
+		if op == nil {
+			switch {
+			case !m.strictRouting:
+				next.ServeHTTP(w, r)
+			case len(allowed) > 0:
+				w.Header().Set("Allow", strings.Join(allowed, ", "))
+				writeProblem(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method), nil)
+			default:
+				writeProblem(w, http.StatusNotFound, fmt.Sprintf("no operation matches %s", r.URL.Path), nil)
+			}
+			return
+		}
+
+		if status, violations := m.validateRequest(r, op, pathParams); len(violations) > 0 {
+			writeProblem(w, status, "request does not match the operation "+op.method+" "+op.path, violations)
+			return
+		}
+		if !m.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if violations := m.validateResponse(op, recorder); len(violations) > 0 {
+			writeProblem(w, http.StatusInternalServerError, "response does not match the operation "+op.method+" "+op.path, violations)
+			return
+		}
+		recorder.writeTo(w)
+	})
+}
+
+// match returns the operation of the request with the values of its path parameters. If only the path matches,
+// the allowed methods are returned instead.
+func (m *Middleware) match(r *http.Request) (*operation, map[string]string, []string) {
+	requestPath := r.URL.EscapedPath()
+	if len(m.basePath) > 0 {
+		if requestPath != m.basePath && !strings.HasPrefix(requestPath, m.basePath+"/") {
+			return nil, nil, nil
+		}
+		requestPath = strings.TrimPrefix(requestPath, m.basePath)
+	}
+	segments := splitPath(requestPath)
+
+	var allowed []string
+	var matchedPath string
+	for _, op := range m.operations {
+		values, ok := matchSegments(op.segments, segments)
+		if !ok || (len(matchedPath) > 0 && op.path != matchedPath) {
+			continue
+		}
+		matchedPath = op.path
+		if op.method == r.Method {
+			return op, values, nil
+		}
+		allowed = append(allowed, op.method)
+	}
+
+	return nil, nil, allowed
+}
+
+func matchSegments(template, segments []string) (map[string]string, bool) {
+	if len(template) != len(segments) {
+		return nil, false
+	}
+
+	values := make(map[string]string)
+	for i, segment := range template {
+		value, err := url.PathUnescape(segments[i])
+		if err != nil {
+			return nil, false
+		}
+		if isTemplate(segment) {
+			values[segment[1:len(segment)-1]] = value
+		} else if segment != value {
+			return nil, false
+		}
+	}
+
+	return values, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isTemplate(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func templatedSegments(segments []string) (count int) {
+	for _, segment := range segments {
+		if isTemplate(segment) {
+			count++
+		}
+	}
+	return
+}
+
+// validateRequest returns the violations of the parameters and body of the request with the status to respond
+func (m *Middleware) validateRequest(r *http.Request, op *operation, pathParams map[string]string) (int, []Violation) {
+	var violations []Violation
+	query := r.URL.Query()
+	for _, p := range op.parameters {
+		var values []string
+		switch p.in {
+		case "path":
+			if value, exists := pathParams[p.name]; exists {
+				values = []string{value}
+			}
+		case "query":
+			values = query[p.name]
+		case "header":
+			values = r.Header.Values(p.name)
+		case "cookie":
+			if cookie, err := r.Cookie(p.name); err == nil {
+				values = []string{cookie.Value}
+			}
+		}
+
+		if len(values) == 0 {
+			if p.required {
+				violations = append(violations, Violation{In: p.in, Name: p.name, Message: "parameter is required"})
+			}
+			continue
+		}
+		value, ok := m.parameterValue(values, p)
+		if !ok {
+			continue
+		}
+		for _, err := range m.validator.validateSchema(value, p.schema, RequestDirection) {
+			violations = append(violations, Violation{In: p.in, Name: p.name, Path: err.Path, Message: err.Message})
+		}
+	}
+
+	if op.body == nil {
+		return http.StatusBadRequest, violations
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return http.StatusBadRequest, append(violations, Violation{In: "body", Message: err.Error()})
+	}
+	if len(body) == 0 {
+		if required, _ := op.body["required"].(bool); required {
+			violations = append(violations, Violation{In: "body", Message: "request body is required"})
+		}
+		return http.StatusBadRequest, violations
+	}
+
+	content := jsonschema.AsObject(op.body["content"])
+	mediaType, schema, documented := mediaTypeSchema(content, r.Header.Get("Content-Type"))
+	if !documented && len(content) > 0 {
+		return http.StatusUnsupportedMediaType, append(violations, Violation{
+			In: "header", Name: "Content-Type", Message: fmt.Sprintf("content type %s is not one of %s", valueOrNone(mediaType), strings.Join(mediaTypes(content), ", ")),
+		})
+	}
+
+	return http.StatusBadRequest, append(violations, m.validateBody(mediaType, body, schema, RequestDirection)...)
+}
+
+// validateResponse returns the violations of the recorded response
+func (m *Middleware) validateResponse(op *operation, recorder *responseRecorder) []Violation {
+	status := strconv.Itoa(recorder.status)
+	response, documented := op.responses[status].(map[string]interface{})
+	if !documented {
+		response, documented = op.responses[status[:1]+"XX"].(map[string]interface{})
+	}
+	if !documented {
+		response, documented = op.responses["DEFAULT"].(map[string]interface{})
+	}
+	if !documented {
+		return []Violation{{In: "status", Message: fmt.Sprintf("status %s is not documented", status)}}
+	}
+
+	content := jsonschema.AsObject(response["content"])
+	if recorder.body.Len() == 0 || len(content) == 0 {
+		return nil
+	}
+	mediaType, schema, documented := mediaTypeSchema(content, recorder.header.Get("Content-Type"))
+	if !documented {
+		return []Violation{{
+			In: "header", Name: "Content-Type", Message: fmt.Sprintf("content type %s is not one of %s", valueOrNone(mediaType), strings.Join(mediaTypes(content), ", ")),
+		}}
+	}
+
+	return m.validateBody(mediaType, recorder.body.Bytes(), schema, ResponseDirection)
+}
+
+// validateBody validates a JSON body, bodies of other media types are not validated
+func (m *Middleware) validateBody(mediaType string, body []byte, schema map[string]interface{}, direction Direction) []Violation {
+	if schema == nil || !isJSON(mediaType) {
+		return nil
+	}
+	value, err := decode(body)
+	if err != nil {
+		return []Violation{{In: "body", Message: "invalid JSON: " + err.Error()}}
+	}
+
+	var violations []Violation
+	for _, err := range m.validator.validateSchema(value, schema, direction) {
+		violations = append(violations, Violation{In: "body", Path: err.Path, Message: err.Message})
+	}
+	return violations
+}
+
+// parameterValue converts the string values of a parameter to the type of its schema. Parameters of type
+// object are not validated.
+func (m *Middleware) parameterValue(values []string, p parameter) (interface{}, bool) {
+	schema := m.resolveSchema(p.schema)
+	switch jsonschema.Type(schema) {
+	case "object":
+		return nil, false
+	case "array":
+		if !p.explode {
+			values = strings.Split(values[0], ",")
+		}
+		items := m.resolveSchema(jsonschema.AsObject(schema["items"]))
+		array := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			array = append(array, scalarValue(value, items))
+		}
+		return array, true
+	}
+
+	return scalarValue(values[0], schema), true
+}
+
+// resolveSchema follows the references of the schema to a component schema, the number of references followed
+// is bounded to not loop on references to themselves
+func (m *Middleware) resolveSchema(schema map[string]interface{}) map[string]interface{} {
+	for i := 0; i < len(m.validator.schemas); i++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			break
+		}
+		resolved, err := m.validator.newRun(AnyDirection).resolve(ref)
+		if err != nil {
+			break
+		}
+		schema = resolved
+	}
+
+	return schema
+}
+
+// scalarValue converts a string to a number or boolean if the schema expects one, otherwise the string is kept
+// to report the mismatching type
+func scalarValue(value string, schema map[string]interface{}) interface{} {
+	switch jsonschema.Type(schema) {
+	case "integer", "number":
+		if number, err := decode([]byte(value)); err == nil {
+			if _, ok := number.(json.Number); ok {
+				return number
+			}
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+			return b
+		}
+	}
+
+	return value
+}
+
+// mediaTypeSchema returns the schema of the content matching the content type, including ranges like application/*
+func mediaTypeSchema(content map[string]interface{}, contentType string) (string, map[string]interface{}, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType, nil, false
+	}
+
+	slash := strings.Index(mediaType, "/")
+	for _, candidate := range []string{mediaType, mediaType[:slash+1] + "*", "*/*"} {
+		if entry, exists := content[candidate]; exists {
+			return mediaType, jsonschema.AsObject(jsonschema.AsObject(entry)["schema"]), true
+		}
+	}
+
+	return mediaType, nil, false
+}
+
+func mediaTypes(content map[string]interface{}) []string {
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// isJSON returns whether the media type is JSON, e.g. application/json or application/merge-patch+json
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// readBody reads the body of the request and replaces it for the handler
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, err
+}
+
+func writeProblem(w http.ResponseWriter, status int, detail string, violations []Violation) {
+	problem := Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail, Errors: violations}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+func valueOrNone(value string) string {
+	if len(value) == 0 {
+		return "none"
+	}
+	return value
+}
+
+// responseRecorder buffers the response of the handler to validate it before it is written
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) writeTo(w http.ResponseWriter) {
+	for key, values := range r.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(r.status)
+	_, _ = w.Write(r.body.Bytes())
+}
@@ -0,0 +1,225 @@
+package validation
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const middlewareDocument = `openapi: 3.0.3
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+        - name: tag
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+              enum: [new, used]
+      responses:
+        200:
+          description: Items
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Item'
+    post:
+      parameters:
+        - name: X-Request-Id
+          in: header
+          required: true
+          schema:
+            type: string
+            format: uuid
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Item'
+      responses:
+        201:
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+        4XX:
+          description: Error
+  /items/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+          minimum: 1
+    get:
+      responses:
+        200:
+          description: Item
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+        maximum: 100
+  schemas:
+    Item:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+`
+
+func serve(t *testing.T, handler http.Handler, method, target, body string, header map[string]string) (int, http.Header, string) {
+	var reader io.Reader
+	if len(body) > 0 {
+		reader = strings.NewReader(body)
+	}
+	request := httptest.NewRequest(method, target, reader)
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Header(), recorder.Body.String()
+}
+
+func Test_Middleware_Request(t *testing.T) {
+	middleware, err := NewMiddleware([]byte(middlewareDocument))
+	assert.NoError(t, err)
+
+	var received string
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	code, _, _ := serve(t, handler, http.MethodGet, "/items?limit=10&tag=new&tag=used", "", nil)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	code, header, body := serve(t, handler, http.MethodGet, "/items?limit=abc&tag=old", "", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ProblemContentType, header.Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "request does not match the operation GET /items",
+		"errors": [
+			{"in": "query", "name": "limit", "message": "expected integer but got string"},
+			{"in": "query", "name": "tag", "path": "/0", "message": "value \"old\" is not one of [\"new\",\"used\"]"}
+		]
+	}`, body)
+
+	code, _, body = serve(t, handler, http.MethodGet, "/items/0", "", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, `{"in":"path","name":"id","message":"number 0 is less than minimum 1"}`)
+
+	code, _, body = serve(t, handler, http.MethodPost, "/items", `{"id": 1, "name": 2}`, map[string]string{"Content-Type": "application/json"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.JSONEq(t, `[
+		{"in": "header", "name": "X-Request-Id", "message": "parameter is required"},
+		{"in": "body", "path": "/id", "message": "property id is read-only"},
+		{"in": "body", "path": "/name", "message": "expected string but got integer"}
+	]`, problemErrors(t, body))
+
+	code, _, body = serve(t, handler, http.MethodPost, "/items", `name=item`, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded", "X-Request-Id": "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	})
+	assert.Equal(t, http.StatusUnsupportedMediaType, code)
+	assert.Contains(t, body, "content type application/x-www-form-urlencoded is not one of application/json")
+
+	code, _, _ = serve(t, handler, http.MethodPost, "/items", `{"name": "item"}`, map[string]string{
+		"Content-Type": "application/json", "X-Request-Id": "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	})
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, `{"name": "item"}`, received)
+
+	// requests without operation are passed to the handler
+	code, _, _ = serve(t, handler, http.MethodDelete, "/items", "", nil)
+	assert.Equal(t, http.StatusNoContent, code)
+}
+
+func Test_Middleware_StrictRouting(t *testing.T) {
+	middleware, err := NewMiddleware([]byte(middlewareDocument), WithStrictRouting(), WithBasePath("/api/"))
+	assert.NoError(t, err)
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	code, _, _ := serve(t, handler, http.MethodGet, "/api/items/1", "", nil)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	code, header, _ := serve(t, handler, http.MethodDelete, "/api/items", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	assert.Equal(t, "GET, POST", header.Get("Allow"))
+
+	code, _, _ = serve(t, handler, http.MethodGet, "/items", "", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func Test_Middleware_Response(t *testing.T) {
+	middleware, err := NewMiddleware([]byte(middlewareDocument), WithResponseValidation())
+	assert.NoError(t, err)
+
+	var status int
+	var response string
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, response)
+	}))
+
+	status, response = http.StatusOK, `[{"id": 1, "name": "item"}]`
+	code, _, body := serve(t, handler, http.MethodGet, "/items", "", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, response, body)
+
+	status, response = http.StatusOK, `[{"id": "1"}]`
+	code, _, body = serve(t, handler, http.MethodGet, "/items", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.JSONEq(t, `[
+		{"in": "body", "path": "/0", "message": "property name is required"},
+		{"in": "body", "path": "/0/id", "message": "expected integer but got string"}
+	]`, problemErrors(t, body))
+
+	status, response = http.StatusTeapot, ``
+	code, _, body = serve(t, handler, http.MethodGet, "/items/1", "", nil)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.JSONEq(t, `[{"in": "status", "message": "status 418 is not documented"}]`, problemErrors(t, body))
+}
+
+// problemErrors returns the errors of a problem response as JSON
+func problemErrors(t *testing.T, body string) string {
+	var problem struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(body), &problem))
+	return string(problem.Errors)
+}
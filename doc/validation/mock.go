@@ -0,0 +1,185 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mock serves example responses for the operations of a document
+type mock struct {
+	middleware *Middleware
+}
+
+// NewMock returns a handler responding to the operations of the YAML or JSON OpenAPI document with examples.
+// Requests are validated like by the Middleware with strict routing, so invalid requests are answered with a problem.
+// The response is chosen by the Prefer header, e.g. "Prefer: code=404" or "Prefer: example=empty", by default the
+// lowest documented 2xx status is used. Its body is the example of the media type, the first of its named examples
+// or an example synthesized from its schema, which uses the examples of the referenced components.
+func NewMock(content []byte, opts ...MiddlewareOption) (http.Handler, error) {
+	m, err := NewMiddleware(content, append([]MiddlewareOption{WithStrictRouting()}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Handler(&mock{middleware: m}), nil
+}
+
+func (h *mock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op, _, _ := h.middleware.match(r)
+	if op == nil {
+		writeProblem(w, http.StatusNotFound, fmt.Sprintf("no operation matches %s", r.URL.Path), nil)
+		return
+	}
+	preferences := parsePrefer(r.Header.Values("Prefer"))
+
+	status, response, err := op.response(preferences["code"])
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error(), []Violation{{In: "header", Name: "Prefer", Message: err.Error()}})
+		return
+	}
+
+	content := jsonschema.AsObject(response["content"])
+	if len(content) == 0 {
+		w.WriteHeader(status)
+		return
+	}
+	mediaType := negotiate(content, r.Header.Get("Accept"))
+	media := jsonschema.AsObject(content[mediaType])
+	example, err := h.example(media, preferences["example"])
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error(), []Violation{{In: "header", Name: "Prefer", Message: err.Error()}})
+		return
+	}
+
+	if strings.Contains(mediaType, "*") {
+		// ranges like application/* or */* are documented for arbitrary content
+		mediaType = "application/json"
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	if text, isString := example.(string); isString && !isJSON(mediaType) {
+		_, _ = w.Write([]byte(text))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(example)
+}
+
+// response returns the status and the response of the operation, the given code or the lowest documented 2xx status
+func (op *operation) response(code string) (int, map[string]interface{}, error) {
+	if len(code) > 0 {
+		status, err := strconv.Atoi(code)
+		if err != nil || status < 100 || status > 599 {
+			return 0, nil, fmt.Errorf("preferred code %s is no HTTP status", code)
+		}
+		for _, key := range []string{code, code[:1] + "XX", "DEFAULT"} {
+			if response, exists := op.responses[key].(map[string]interface{}); exists {
+				return status, response, nil
+			}
+		}
+		return 0, nil, fmt.Errorf("status %s is not documented for %s %s", code, op.method, op.path)
+	}
+
+	statuses := make([]string, 0, len(op.responses))
+	for status := range op.responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, key := range statuses {
+		if strings.HasPrefix(key, "2") {
+			status, err := strconv.Atoi(key)
+			if err != nil {
+				status = http.StatusOK
+			}
+			return status, jsonschema.AsObject(op.responses[key]), nil
+		}
+	}
+	if response, exists := op.responses["DEFAULT"].(map[string]interface{}); exists {
+		return http.StatusOK, response, nil
+	}
+	for _, key := range statuses {
+		if status, err := strconv.Atoi(key); err == nil {
+			return status, jsonschema.AsObject(op.responses[key]), nil
+		}
+	}
+
+	return 0, nil, fmt.Errorf("no response is documented for %s %s", op.method, op.path)
+}
+
+// example returns the example of the media type, the named or first of its examples or a synthesized example
+func (h *mock) example(media map[string]interface{}, name string) (interface{}, error) {
+	examples := jsonschema.AsObject(media["examples"])
+	if len(name) > 0 {
+		example, exists := examples[name].(map[string]interface{})
+		if !exists {
+			return nil, fmt.Errorf("example %s is not documented", name)
+		}
+		return h.exampleValue(example)
+	}
+
+	if example, exists := media["example"]; exists {
+		return example, nil
+	}
+	if len(examples) > 0 {
+		names := make([]string, 0, len(examples))
+		for name := range examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return h.exampleValue(jsonschema.AsObject(examples[names[0]]))
+	}
+
+	return doc.SynthesizeExample(jsonschema.AsObject(media["schema"]), jsonschema.AsObject(h.middleware.components["schemas"])), nil
+}
+
+// exampleValue returns the value of an example object, which may reference #/components/examples
+func (h *mock) exampleValue(example map[string]interface{}) (interface{}, error) {
+	resolved, err := h.middleware.resolveComponent(example, "examples")
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved["value"], nil
+}
+
+// parsePrefer returns the preferences of Prefer headers, e.g. "code=404, example=empty"
+func parsePrefer(headers []string) map[string]string {
+	preferences := make(map[string]string)
+	for _, header := range headers {
+		for _, preference := range strings.Split(header, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(preference), "=")
+			preferences[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+
+	return preferences
+}
+
+// negotiate returns the media type of the content accepted by the Accept header, preferring JSON
+func negotiate(content map[string]interface{}, accept string) string {
+	available := mediaTypes(content)
+	for _, entry := range strings.Split(accept, ",") {
+		accepted, _, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err != nil || accepted == "*/*" {
+			continue
+		}
+		for _, mediaType := range available {
+			if mediaType == accepted || (strings.HasSuffix(accepted, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(accepted, "*"))) {
+				return mediaType
+			}
+		}
+	}
+
+	for _, mediaType := range available {
+		if isJSON(mediaType) {
+			return mediaType
+		}
+	}
+	return available[0]
+}
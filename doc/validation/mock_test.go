@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+const mockDocument = `openapi: 3.0.3
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      responses:
+        200:
+          description: Items
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Item'
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Item'
+      responses:
+        201:
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+        default:
+          description: Error
+  /items/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        200:
+          description: Item
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+              examples:
+                widget:
+                  value: {id: 1, name: Widget}
+                gadget:
+                  $ref: '#/components/examples/Gadget'
+        404:
+          description: Not found
+          content:
+            application/problem+json:
+              example: {title: Not Found, status: 404}
+    delete:
+      responses:
+        204:
+          description: Deleted
+components:
+  examples:
+    Gadget:
+      value: {id: 2, name: Gadget}
+  schemas:
+    Item:
+      type: object
+      required: [name]
+      properties:
+        id:
+          type: integer
+          minimum: 1
+          readOnly: true
+        name:
+          type: string
+          example: Widget
+`
+
+func Test_Mock(t *testing.T) {
+	handler, err := NewMock([]byte(mockDocument))
+	assert.NoError(t, err)
+
+	code, header, body := serve(t, handler, http.MethodGet, "/items", "", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.JSONEq(t, `[{"id": 1, "name": "Widget"}]`, body)
+
+	// the first named example is used by default
+	code, _, body = serve(t, handler, http.MethodGet, "/items/1", "", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"id": 2, "name": "Gadget"}`, body)
+
+	code, _, body = serve(t, handler, http.MethodGet, "/items/1", "", map[string]string{"Prefer": "example=widget"})
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"id": 1, "name": "Widget"}`, body)
+
+	code, header, body = serve(t, handler, http.MethodGet, "/items/1", "", map[string]string{"Prefer": "code=404"})
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, ProblemContentType, header.Get("Content-Type"))
+	assert.JSONEq(t, `{"title": "Not Found", "status": 404}`, body)
+
+	code, _, body = serve(t, handler, http.MethodGet, "/items/1", "", map[string]string{"Prefer": "code=500"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.JSONEq(t, `[{"in": "header", "name": "Prefer", "message": "status 500 is not documented for GET /items/{id}"}]`, problemErrors(t, body))
+
+	code, _, body = serve(t, handler, http.MethodGet, "/items/abc", "", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.JSONEq(t, `[{"in": "path", "name": "id", "message": "expected integer but got string"}]`, problemErrors(t, body))
+
+	code, _, body = serve(t, handler, http.MethodPost, "/items", `{"name": 1}`, map[string]string{"Content-Type": "application/json"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.JSONEq(t, `[{"in": "body", "path": "/name", "message": "expected string but got integer"}]`, problemErrors(t, body))
+
+	code, _, body = serve(t, handler, http.MethodPost, "/items", `{"name": "New"}`, map[string]string{"Content-Type": "application/json"})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.JSONEq(t, `{"id": 1, "name": "Widget"}`, body)
+
+	code, _, body = serve(t, handler, http.MethodDelete, "/items/1", "", nil)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Empty(t, body)
+
+	code, header, _ = serve(t, handler, http.MethodPut, "/items/1", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	assert.Equal(t, "GET, DELETE", header.Get("Allow"))
+}
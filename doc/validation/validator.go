@@ -0,0 +1,684 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"gopkg.in/yaml.v3"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Direction is the direction of a payload, it decides how readOnly and writeOnly properties are validated
+type Direction int
+
+const (
+	// AnyDirection ignores readOnly and writeOnly
+	AnyDirection Direction = iota
+	// RequestDirection rejects readOnly properties, which are also not required in requests
+	RequestDirection
+	// ResponseDirection rejects writeOnly properties, which are also not required in responses
+	ResponseDirection
+)
+
+// Error is a violation of a schema by the value at the JSON pointer Path, the empty path is the whole value
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error returns the violation prefixed by its path
+func (e Error) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Errors are all violations of a validated value ordered by path
+type Errors []Error
+
+// Error joins the violations
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Option configures optional behaviour of the Validator
+type Option func(v *Validator)
+
+// WithFormat validates strings with the format name by the check, which returns an error for invalid values.
+// It replaces a built-in check of the same format.
+func WithFormat(name string, check func(value string) error) Option {
+	return func(v *Validator) {
+		v.formats[name] = check
+	}
+}
+
+// WithNullForOptional accepts null for properties which are not required. encoding/json writes nil pointers,
+// slices and maps as null unless the field is tagged omitempty, but the generator does not mark them as nullable.
+func WithNullForOptional() Option {
+	return func(v *Validator) {
+		v.nullForOptional = true
+	}
+}
+
+// Validator validates JSON payloads against the component schemas of a document. It supports the keywords the
+// generator emits, e.g. $ref, type, format, enum, required, properties, additionalProperties, items, allOf, anyOf
+// and oneOf with discriminator, as well as the constraints of OpenAPI 3.0 and 3.1 schemas. A Validator is safe for
+// concurrent use.
+type Validator struct {
+	schemas         map[string]map[string]interface{}
+	formats         map[string]func(value string) error
+	nullForOptional bool
+	patterns        sync.Map
+}
+
+// New returns a validator of the generated schemas, which are named by doc.ComponentName. The schemas should be
+// generated WithGoTypeExtension, so that the names match the references between them.
+func New(schemas []spec.Schema, opts ...Option) (*Validator, error) {
+	v := newValidator(opts)
+	for _, schema := range schemas {
+		raw, err := json.Marshal(schema)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		v.schemas[doc.ComponentName(schema)] = jsonschema.AsObject(value)
+	}
+
+	return v, nil
+}
+
+// NewFromDocument returns a validator of the component schemas of the YAML or JSON OpenAPI document
+func NewFromDocument(content []byte, opts ...Option) (*Validator, error) {
+	document, err := parseDocument(content)
+	if err != nil {
+		return nil, err
+	}
+
+	v := newValidator(opts)
+	for name, schema := range jsonschema.AsObject(jsonschema.AsObject(document["components"])["schemas"]) {
+		v.schemas[name] = jsonschema.AsObject(schema)
+	}
+
+	return v, nil
+}
+
+// parseDocument parses a YAML or JSON document into decoded JSON values. Keys of mappings are always strings,
+// e.g. the unquoted status codes of responses.
+func parseDocument(content []byte) (map[string]interface{}, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+	value, err := fromNode(&root)
+	if err != nil {
+		return nil, err
+	}
+
+	// decode numbers as json.Number like the validated values
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decode(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonschema.AsObject(decoded), nil
+}
+
+func fromNode(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return fromNode(node.Content[0])
+	case yaml.AliasNode:
+		return fromNode(node.Alias)
+	case yaml.MappingNode:
+		object := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := fromNode(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			object[node.Content[i].Value] = value
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		array := make([]interface{}, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := fromNode(child)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	}
+
+	var value interface{}
+	err := node.Decode(&value)
+	return value, err
+}
+
+func newValidator(opts []Option) *Validator {
+	v := &Validator{schemas: make(map[string]map[string]interface{}), formats: make(map[string]func(string) error)}
+	for name, check := range builtinFormats {
+		v.formats[name] = check
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Schemas returns the sorted names of the schemas known to the validator
+func (v *Validator) Schemas() []string {
+	names := make([]string, 0, len(v.schemas))
+	for name := range v.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Validate validates the JSON data against the named schema. It returns Errors if the data violates the schema
+// and another error if the data is no JSON or the schema is unknown.
+func (v *Validator) Validate(name string, data []byte) error {
+	return v.ValidateDirection(name, data, AnyDirection)
+}
+
+// ValidateValue validates the Go value as encoded by encoding/json against the named schema
+func (v *Validator) ValidateValue(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return v.Validate(name, data)
+}
+
+// ValidateDirection validates the JSON data against the named schema as payload of the given direction
+func (v *Validator) ValidateDirection(name string, data []byte, direction Direction) error {
+	schema, exists := v.schemas[name]
+	if !exists {
+		return fmt.Errorf("unknown schema %s", name)
+	}
+	value, err := decode(data)
+	if err != nil {
+		return err
+	}
+
+	if errs := v.validateSchema(value, schema, direction); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateSchema validates the decoded value against the schema and returns the violations ordered by path
+func (v *Validator) validateSchema(value interface{}, schema map[string]interface{}, direction Direction) Errors {
+	errs := v.newRun(direction).validate("", value, schema)
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Path < errs[j].Path
+	})
+	return errs
+}
+
+// decode decodes JSON keeping numbers as json.Number to not lose the precision of large integers
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+
+	return value, nil
+}
+
+// run is a single validation of a value
+type run struct {
+	*Validator
+	direction Direction
+}
+
+func (v *Validator) newRun(direction Direction) *run {
+	return &run{Validator: v, direction: direction}
+}
+
+func (r *run) validate(path string, value interface{}, schema map[string]interface{}) Errors {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := r.resolve(ref)
+		if err != nil {
+			return Errors{{Path: path, Message: err.Error()}}
+		}
+		return r.validate(path, value, resolved)
+	}
+
+	var errs Errors
+	if !r.validateType(path, value, schema, &errs) {
+		return errs
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf("value %s is not one of %s", jsonschema.JSONString(value), jsonschema.JSONString(enum))})
+	}
+	if constant, ok := schema["const"]; ok && !equal(constant, value) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf("value %s is not %s", jsonschema.JSONString(value), jsonschema.JSONString(constant))})
+	}
+
+	switch value := value.(type) {
+	case string:
+		r.validateString(path, value, schema, &errs)
+	case json.Number:
+		r.validateNumber(path, value, schema, &errs)
+	case []interface{}:
+		r.validateArray(path, value, schema, &errs)
+	case map[string]interface{}:
+		r.validateObject(path, value, schema, &errs)
+	}
+
+	r.validateComposition(path, value, schema, &errs)
+	return errs
+}
+
+// resolve returns the component schema of a local reference
+func (r *run) resolve(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, jsonschema.ComponentRefPrefix) {
+		return nil, fmt.Errorf("reference %s does not point to a component schema", ref)
+	}
+	schema, exists := r.schemas[jsonschema.UnescapePointer(strings.TrimPrefix(ref, jsonschema.ComponentRefPrefix))]
+	if !exists {
+		return nil, fmt.Errorf("reference %s does not resolve", ref)
+	}
+
+	return schema, nil
+}
+
+// validateType reports a value not matching the type of the schema and returns whether further keywords apply
+func (r *run) validateType(path string, value interface{}, schema map[string]interface{}, errs *Errors) bool {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, entry := range t {
+			if s, ok := entry.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		// an untyped schema accepts any value, null skips the other keywords of a nullable one, e.g. of oneOf
+		if nullable, _ := schema["nullable"].(bool); nullable && value == nil {
+			return false
+		}
+		return true
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		types = append(types, "null")
+	}
+
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return actual != "null"
+		}
+	}
+
+	*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("expected %s but got %s", strings.Join(types, " or "), actual)})
+	return false
+}
+
+func (r *run) validateString(path string, value string, schema map[string]interface{}, errs *Errors) {
+	length := utf8.RuneCountInString(value)
+	if min, ok := jsonschema.Number(schema["minLength"]); ok && float64(length) < min {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("length %d is less than minLength %v", length, schema["minLength"])})
+	}
+	if max, ok := jsonschema.Number(schema["maxLength"]); ok && float64(length) > max {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("length %d is greater than maxLength %v", length, schema["maxLength"])})
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re, err := r.pattern(pattern); err == nil && !re.MatchString(value) {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("value does not match pattern %s", pattern)})
+		}
+	}
+	if format, ok := schema["format"].(string); ok {
+		if check, exists := r.formats[format]; exists {
+			if err := check(value); err != nil {
+				*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("value is no valid %s: %v", format, err)})
+			}
+		}
+	}
+}
+
+// pattern returns the compiled pattern, invalid patterns are ignored as they are reported by doc.ValidateDocument
+func (r *run) pattern(pattern string) (*regexp.Regexp, error) {
+	if re, exists := r.patterns.Load(pattern); exists {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	r.patterns.Store(pattern, re)
+
+	return re, nil
+}
+
+func (r *run) validateNumber(path string, value json.Number, schema map[string]interface{}, errs *Errors) {
+	f, err := value.Float64()
+	if err != nil {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is out of range", value)})
+		return
+	}
+
+	switch schema["format"] {
+	case "int32":
+		if f < math.MinInt32 || f > math.MaxInt32 {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is out of range of int32", value)})
+		}
+	case "int64":
+		if _, err := strconv.ParseInt(value.String(), 10, 64); err != nil && !strings.ContainsAny(value.String(), ".eE") {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is out of range of int64", value)})
+		}
+	}
+
+	if min, ok := jsonschema.Number(schema["minimum"]); ok {
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive && f <= min {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is not greater than exclusive minimum %v", value, schema["minimum"])})
+		} else if f < min {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is less than minimum %v", value, schema["minimum"])})
+		}
+	}
+	if max, ok := jsonschema.Number(schema["maximum"]); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive && f >= max {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is not less than exclusive maximum %v", value, schema["maximum"])})
+		} else if f > max {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is greater than maximum %v", value, schema["maximum"])})
+		}
+	}
+	// OpenAPI 3.1 exclusive bounds are numbers
+	if min, ok := jsonschema.Number(schema["exclusiveMinimum"]); ok && f <= min {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is not greater than exclusive minimum %v", value, schema["exclusiveMinimum"])})
+	}
+	if max, ok := jsonschema.Number(schema["exclusiveMaximum"]); ok && f >= max {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is not less than exclusive maximum %v", value, schema["exclusiveMaximum"])})
+	}
+	if multipleOf, ok := jsonschema.Number(schema["multipleOf"]); ok && multipleOf > 0 {
+		if quotient := f / multipleOf; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("number %s is not a multiple of %v", value, schema["multipleOf"])})
+		}
+	}
+}
+
+func (r *run) validateArray(path string, value []interface{}, schema map[string]interface{}, errs *Errors) {
+	if min, ok := jsonschema.Number(schema["minItems"]); ok && float64(len(value)) < min {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("%d items are less than minItems %v", len(value), schema["minItems"])})
+	}
+	if max, ok := jsonschema.Number(schema["maxItems"]); ok && float64(len(value)) > max {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("%d items are more than maxItems %v", len(value), schema["maxItems"])})
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := 0; j < i; j++ {
+				if equal(value[i], value[j]) {
+					*errs = append(*errs, Error{Path: fmt.Sprintf("%s/%d", path, i), Message: fmt.Sprintf("item equals item %d", j)})
+					break
+				}
+			}
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range value {
+			*errs = append(*errs, r.validate(fmt.Sprintf("%s/%d", path, i), item, items)...)
+		}
+	}
+}
+
+func (r *run) validateObject(path string, value map[string]interface{}, schema map[string]interface{}, errs *Errors) {
+	if min, ok := jsonschema.Number(schema["minProperties"]); ok && float64(len(value)) < min {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("%d properties are less than minProperties %v", len(value), schema["minProperties"])})
+	}
+	if max, ok := jsonschema.Number(schema["maxProperties"]); ok && float64(len(value)) > max {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("%d properties are more than maxProperties %v", len(value), schema["maxProperties"])})
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]struct{})
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, entry := range list {
+			name, _ := entry.(string)
+			required[name] = struct{}{}
+			if _, exists := value[name]; !exists && !r.skipProperty(jsonschema.AsObject(properties[name])) {
+				*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("property %s is required", name)})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "/" + jsonschema.EscapePointer(name)
+		property, defined := properties[name].(map[string]interface{})
+		if !defined {
+			r.validateAdditional(propertyPath, name, value[name], schema, errs)
+			continue
+		}
+
+		if r.skipProperty(property) {
+			*errs = append(*errs, Error{Path: propertyPath, Message: fmt.Sprintf("property %s is %s", name, r.accessOf())})
+			continue
+		}
+		if _, isRequired := required[name]; value[name] == nil && !isRequired && r.nullForOptional {
+			continue
+		}
+		*errs = append(*errs, r.validate(propertyPath, value[name], property)...)
+	}
+}
+
+// validateAdditional validates a property which is not defined by the properties of the schema
+func (r *run) validateAdditional(path, name string, value interface{}, schema map[string]interface{}, errs *Errors) {
+	switch additional := schema["additionalProperties"].(type) {
+	case bool:
+		if !additional {
+			*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("property %s is not allowed", name)})
+		}
+	case map[string]interface{}:
+		*errs = append(*errs, r.validate(path, value, additional)...)
+	}
+}
+
+// skipProperty returns whether the property must not be sent in the direction of the run
+func (r *run) skipProperty(property map[string]interface{}) bool {
+	switch r.direction {
+	case RequestDirection:
+		readOnly, _ := property["readOnly"].(bool)
+		return readOnly
+	case ResponseDirection:
+		writeOnly, _ := property["writeOnly"].(bool)
+		return writeOnly
+	}
+
+	return false
+}
+
+func (r *run) accessOf() string {
+	if r.direction == RequestDirection {
+		return "read-only"
+	}
+	return "write-only"
+}
+
+func (r *run) validateComposition(path string, value interface{}, schema map[string]interface{}, errs *Errors) {
+	for _, subschema := range jsonschema.AsArray(schema["allOf"]) {
+		*errs = append(*errs, r.validate(path, value, jsonschema.AsObject(subschema))...)
+	}
+
+	if not, ok := schema["not"].(map[string]interface{}); ok && len(r.validate(path, value, not)) == 0 {
+		*errs = append(*errs, Error{Path: path, Message: "value must not match the schema of not"})
+	}
+
+	if anyOf := jsonschema.AsArray(schema["anyOf"]); len(anyOf) > 0 {
+		if matches, _ := r.matches(path, value, anyOf); len(matches) == 0 {
+			*errs = append(*errs, Error{Path: path, Message: "value does not match any schema of anyOf"})
+		}
+	}
+
+	oneOf := jsonschema.AsArray(schema["oneOf"])
+	if len(oneOf) == 0 {
+		return
+	}
+	if discriminator, ok := schema["discriminator"].(map[string]interface{}); ok {
+		if object, isObject := value.(map[string]interface{}); isObject {
+			r.validateDiscriminator(path, object, discriminator, oneOf, errs)
+			return
+		}
+	}
+
+	matches, mismatches := r.matches(path, value, oneOf)
+	switch {
+	case len(matches) == 0 && len(oneOf) == 1:
+		*errs = append(*errs, mismatches[0]...)
+	case len(matches) == 0:
+		*errs = append(*errs, Error{Path: path, Message: "value does not match any schema of oneOf"})
+	case len(matches) > 1:
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("value matches more than one schema of oneOf: %s", jsonschema.JSONString(matches))})
+	}
+}
+
+// matches returns the indices of the matching schemas and the violations of the other schemas
+func (r *run) matches(path string, value interface{}, schemas []interface{}) (matches []int, mismatches []Errors) {
+	for i, subschema := range schemas {
+		if subErrs := r.validate(path, value, jsonschema.AsObject(subschema)); len(subErrs) == 0 {
+			matches = append(matches, i)
+		} else {
+			mismatches = append(mismatches, subErrs)
+		}
+	}
+
+	return
+}
+
+// validateDiscriminator validates an object against the schema of oneOf selected by the discriminator property
+func (r *run) validateDiscriminator(path string, value map[string]interface{}, discriminator map[string]interface{}, oneOf []interface{}, errs *Errors) {
+	propertyName, _ := discriminator["propertyName"].(string)
+	kind, ok := value[propertyName].(string)
+	if !ok {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf("discriminator property %s is required", propertyName)})
+		return
+	}
+
+	ref, mapped := jsonschema.DiscriminatorRef(discriminator, oneOf, kind)
+	if !mapped {
+		*errs = append(*errs, Error{Path: path + "/" + jsonschema.EscapePointer(propertyName), Message: fmt.Sprintf("discriminator value %s is not mapped to a schema", kind)})
+		return
+	}
+
+	*errs = append(*errs, r.validate(path, value, map[string]interface{}{"$ref": ref})...)
+}
+
+// typeOf returns the JSON schema type of a decoded JSON value
+func typeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		// integers may exceed the range of float64, numbers like 1.0 are integers as well
+		if !strings.ContainsAny(value.String(), ".eE") {
+			return "integer"
+		}
+		if f, err := value.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// equal compares decoded JSON values, numbers are equal if their values are
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, errA := a.Float64()
+		fb, errB := b.Float64()
+		return a == b || (errA == nil && errB == nil && fa == fb)
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			if other, exists := b[key]; !exists || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return a == b
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if equal(candidate, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,209 @@
+package validation
+
+import (
+	"errors"
+	"github.com/mrahbar/gostruct2openapi/doc"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func errorLines(t *testing.T, err error) []string {
+	if err == nil {
+		return nil
+	}
+	var errs Errors
+	if !assert.True(t, errors.As(err, &errs), "expected validation errors, got %v", err) {
+		return nil
+	}
+	var lines []string
+	for _, e := range errs {
+		lines = append(lines, e.Error())
+	}
+	return lines
+}
+
+func generatedValidator(t *testing.T, filter string) *Validator {
+	generator := doc.NewOpenapiGenerator(regexp.MustCompile(filter), "json", doc.WithGoTypeExtension())
+	schemas, err := generator.DocumentStruct("../testdata")
+	assert.NoError(t, err)
+	v, err := New(schemas)
+	assert.NoError(t, err)
+	return v
+}
+
+func Test_Validate_Generated(t *testing.T) {
+	v := generatedValidator(t, "^TestItem$")
+	assert.Equal(t, []string{"TestItem", "TestUnderlyingStruct"}, v.Schemas())
+
+	item := `{"id": "1", "name": "item", "secret": "s", "owner": {"UnderlyingFieldB": "b", "UnderlyingFieldC": 1.5, "UnderlyingFieldD": true}}`
+	assert.NoError(t, v.Validate("TestItem", []byte(item)))
+
+	err := v.Validate("TestItem", []byte(`{"id": 1, "owner": {"UnderlyingFieldC": "1.5"}}`))
+	assert.Equal(t, []string{
+		"property name is required",
+		"/id: expected string but got integer",
+		"/owner/UnderlyingFieldC: expected number but got string",
+	}, errorLines(t, err))
+}
+
+func Test_ValidateDirection(t *testing.T) {
+	v := generatedValidator(t, "^TestItem$")
+
+	err := v.ValidateDirection("TestItem", []byte(`{"id": "1", "name": "item"}`), RequestDirection)
+	assert.Equal(t, []string{"/id: property id is read-only"}, errorLines(t, err))
+	assert.NoError(t, v.ValidateDirection("TestItem", []byte(`{"name": "item", "secret": "s"}`), RequestDirection))
+
+	err = v.ValidateDirection("TestItem", []byte(`{"id": "1", "name": "item", "secret": "s"}`), ResponseDirection)
+	assert.Equal(t, []string{"/secret: property secret is write-only"}, errorLines(t, err))
+}
+
+func Test_Validate_Discriminator(t *testing.T) {
+	v := generatedValidator(t, "^TestPolymorphicStruct$")
+
+	valid := `{"FieldA": {"kind": "circle", "radius": 1}, "FieldB": [{"kind": "square", "length": 2}], "FieldC": {"UnderlyingFieldB": "b", "radius": "r"}}`
+	assert.NoError(t, v.Validate("TestPolymorphicStruct", []byte(valid)))
+	// nil interfaces are encoded as null
+	assert.NoError(t, v.Validate("TestPolymorphicStruct", []byte(`{"FieldA": null, "FieldB": [null], "FieldC": null}`)))
+
+	invalid := `{"FieldA": {"kind": "circle", "radius": "1"}, "FieldB": [{"kind": "square"}, {"kind": "triangle"}, {"length": 2}]}`
+	assert.Equal(t, []string{
+		"/FieldA/radius: expected number but got string",
+		"/FieldB/1/kind: discriminator value triangle is not mapped to a schema",
+		"/FieldB/2: discriminator property kind is required",
+	}, errorLines(t, v.Validate("TestPolymorphicStruct", []byte(invalid))))
+	assert.Equal(t, []string{"/FieldC: value does not match any schema of anyOf"},
+		errorLines(t, v.Validate("TestPolymorphicStruct", []byte(`{"FieldC": "circle"}`))))
+}
+
+func Test_NewFromDocument(t *testing.T) {
+	document := `openapi: 3.0.3
+components:
+  schemas:
+    Order:
+      type: object
+      required: [id, status]
+      additionalProperties: false
+      properties:
+        id:
+          type: string
+          format: uuid
+        status:
+          type: string
+          enum: [open, closed]
+        quantity:
+          type: integer
+          format: int32
+          minimum: 1
+        created:
+          type: string
+          format: date-time
+        note:
+          type: string
+          nullable: true
+          maxLength: 5
+        code:
+          type: string
+          pattern: '^[A-Z]{3}$'
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+        tags:
+          type: array
+          uniqueItems: true
+          items:
+            type: string
+        payment:
+          oneOf:
+            - $ref: '#/components/schemas/Card'
+            - $ref: '#/components/schemas/Transfer'
+    Card:
+      type: object
+      required: [number]
+      properties:
+        number:
+          type: string
+    Transfer:
+      type: object
+      required: [iban]
+      properties:
+        iban:
+          type: string
+`
+	v, err := NewFromDocument([]byte(document))
+	assert.NoError(t, err)
+
+	valid := `{"id": "3fa85f64-5717-4562-b3fc-2c963f66afa6", "status": "open", "quantity": 2, "created": "2024-01-02T15:04:05Z",
+		"note": null, "code": "EUR", "labels": {"a": "b"}, "tags": ["x", "y"], "payment": {"number": "4111"}}`
+	assert.NoError(t, v.Validate("Order", []byte(valid)))
+
+	invalid := `{"id": "42", "status": "paid", "quantity": 3000000000, "created": "yesterday", "note": "too long",
+		"code": "eur", "labels": {"a": 1}, "tags": ["x", "x"], "payment": {"number": "4111", "iban": "DE00"}, "extra": true}`
+	assert.Equal(t, []string{
+		"/code: value does not match pattern ^[A-Z]{3}$",
+		"/created: value is no valid date-time: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\"",
+		"/extra: property extra is not allowed",
+		"/id: value is no valid uuid: expected 8-4-4-4-12 hexadecimal digits",
+		"/labels/a: expected string but got integer",
+		"/note: length 8 is greater than maxLength 5",
+		"/payment: value matches more than one schema of oneOf: [0,1]",
+		"/quantity: number 3000000000 is out of range of int32",
+		"/status: value \"paid\" is not one of [\"open\",\"closed\"]",
+		"/tags/1: item equals item 0",
+	}, errorLines(t, v.Validate("Order", []byte(invalid))))
+
+	assert.Error(t, v.Validate("Order", []byte(`{"id": `)))
+	assert.EqualError(t, v.Validate("Missing", []byte(`{}`)), "unknown schema Missing")
+}
+
+func Test_ValidateValue(t *testing.T) {
+	type owner struct {
+		UnderlyingFieldB string
+	}
+	type item struct {
+		ID    string  `json:"id"`
+		Name  *string `json:"name"`
+		Owner *owner  `json:"owner"`
+	}
+
+	generator := doc.NewOpenapiGenerator(regexp.MustCompile("^TestItem$"), "json", doc.WithGoTypeExtension())
+	schemas, err := generator.DocumentStruct("../testdata")
+	assert.NoError(t, err)
+
+	v, err := New(schemas)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/name: expected string but got null",
+		"/owner: expected object but got null",
+	}, errorLines(t, v.ValidateValue("TestItem", item{ID: "1"})))
+
+	v, err = New(schemas, WithNullForOptional(), WithFormat("uuid", func(string) error { return nil }))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/name: expected string but got null",
+	}, errorLines(t, v.ValidateValue("TestItem", item{ID: "1"})))
+}
+
+func Test_Validate_SynthesizedExamples(t *testing.T) {
+	generator := doc.NewOpenapiGenerator(regexp.MustCompile(".*"), "json",
+		doc.WithGoTypeExtension(), doc.WithSchemaVariants(doc.CreateVariant, doc.PatchVariant))
+	schemas, err := generator.DocumentStruct("../testdata")
+	assert.NoError(t, err)
+	examples, err := doc.SynthesizeExamples(schemas)
+	assert.NoError(t, err)
+
+	v, err := New(schemas)
+	assert.NoError(t, err)
+	lenient, err := New(schemas, WithNullForOptional())
+	assert.NoError(t, err)
+	for _, name := range v.Schemas() {
+		if strings.HasPrefix(name, "TestLiteralOrder") {
+			// the example of the variable TestLiteralOrderExample encodes its nil Parent as null
+			assert.NoError(t, lenient.ValidateValue(name, examples[name]), name)
+			continue
+		}
+		assert.NoError(t, v.ValidateValue(name, examples[name]), name)
+	}
+}
@@ -0,0 +1,95 @@
+package doc
+
+import (
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/util"
+	"strings"
+)
+
+// SchemaVariant names an additional schema derived from a struct schema, e.g. for request bodies.
+// The variant schema is registered as the struct name suffixed by the variant, e.g. ItemCreate.
+type SchemaVariant string
+
+const (
+	// CreateVariant omits all readOnly properties
+	CreateVariant SchemaVariant = "Create"
+	// UpdateVariant omits the optional readOnly properties, required ones like the id of the replaced resource are kept writable
+	UpdateVariant SchemaVariant = "Update"
+	// PatchVariant omits all readOnly properties and makes all properties optional
+	PatchVariant SchemaVariant = "Patch"
+)
+
+// derive returns a copy of the struct schema adjusted to the variant. References to other
+// struct schemas are replaced by references to their variant.
+func (v SchemaVariant) derive(structName string, schema spec.Schema) spec.Schema {
+	derived := copySchema(schema)
+	derived.ID = structName + string(v)
+	if len(derived.Title) > 0 {
+		derived.Title += " " + string(v)
+	}
+	v.adjust(&derived)
+
+	return derived
+}
+
+func (v SchemaVariant) adjust(schema *spec.Schema) {
+	if ref := schema.Ref.String(); strings.HasPrefix(ref, jsonschema.ComponentRefPrefix) {
+		schema.Ref = spec.MustCreateRef(ref + string(v))
+	}
+
+	for name, property := range schema.Properties {
+		if property.ReadOnly && (v != UpdateVariant || !util.Contains(schema.Required, name)) {
+			delete(schema.Properties, name)
+			continue
+		}
+		property.ReadOnly = false
+		v.adjust(&property)
+		schema.Properties[name] = property
+	}
+
+	var required []string
+	if v != PatchVariant {
+		for _, name := range schema.Required {
+			if _, exists := schema.Properties[name]; exists {
+				required = append(required, name)
+			}
+		}
+	}
+	schema.Required = required
+
+	if schema.Items != nil && schema.Items.Schema != nil {
+		v.adjust(schema.Items.Schema)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		v.adjust(schema.AdditionalProperties.Schema)
+	}
+	for i := range schema.AllOf {
+		v.adjust(&schema.AllOf[i])
+	}
+	for i := range schema.OneOf {
+		v.adjust(&schema.OneOf[i])
+	}
+	for i := range schema.AnyOf {
+		v.adjust(&schema.AnyOf[i])
+	}
+	if discriminator, ok := schema.ExtraProps["discriminator"].(map[string]interface{}); ok {
+		if mapping, ok := discriminator["mapping"].(map[string]interface{}); ok {
+			for value, ref := range mapping {
+				mapping[value] = ref.(string) + string(v)
+			}
+		}
+	}
+}
+
+// copySchema returns a deep copy of the given schema
+func copySchema(schema spec.Schema) spec.Schema {
+	if bytes, err := json.Marshal(schema); err == nil {
+		if copied, err := unmarshalSchema(bytes); err == nil {
+			return copied
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,293 @@
+package doc
+
+import (
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/mrahbar/gostruct2openapi/doc/internal"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/jsonschema"
+	"github.com/mrahbar/gostruct2openapi/doc/internal/util"
+	"sort"
+	"strings"
+)
+
+// GoTypeExtension names the Go type described by a component schema, either fully qualified,
+// e.g. github.com/acme/api/model.Item, qualified by the package name, e.g. model.Item, or by its name only
+const GoTypeExtension = "x-go-type"
+
+// Mismatch is a difference between a component of an existing document and the schema generated from its Go type
+type Mismatch struct {
+	// Schema is the name of the component
+	Schema string `json:"schema"`
+	// GoType is the fully qualified name of the Go type the component is mapped to, empty if there is none
+	GoType string `json:"goType,omitempty"`
+	// Path is the JSON pointer of the mismatch within the component
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String returns the mismatch as a line of a report
+func (m Mismatch) String() string {
+	if len(m.GoType) == 0 {
+		return fmt.Sprintf("%s%s: %s", m.Schema, m.Path, m.Message)
+	}
+	return fmt.Sprintf("%s%s (%s): %s", m.Schema, m.Path, m.GoType, m.Message)
+}
+
+// VerifyReport lists the mismatches between an existing document and the Go types it describes
+type VerifyReport struct {
+	// Mismatches are ordered by component and path
+	Mismatches []Mismatch
+	// Unmapped are the components which neither have the x-go-type extension nor the name of a Go type
+	Unmapped []string
+}
+
+// VerifyDocument checks the components of a hand-written YAML or JSON document against the schemas generated
+// from the Go types they describe. The schemas must be generated WithGoTypeExtension. A component is mapped by
+// its x-go-type extension or otherwise by its name, which is either the ComponentName of a generated schema or
+// the name of the Go type. Field names, types, formats, references and required-ness are compared.
+func VerifyDocument(document []byte, schemas []spec.Schema) (*VerifyReport, error) {
+	components, err := ParseDocumentSchemas(document)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(map[string]map[string]interface{})
+	for _, component := range components {
+		if expected[component.ID], err = schemaValue(component); err != nil {
+			return nil, err
+		}
+	}
+	generated, err := schemaValues(schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &verifier{report: &VerifyReport{}, goTypes: make(map[string]string)}
+	byGoType := make(map[string]map[string]interface{})
+	var goTypes []string
+	for name, schema := range generated {
+		if goType, ok := schema[GoTypeExtension].(string); ok {
+			byGoType[goType] = schema
+			goTypes = append(goTypes, goType)
+		} else {
+			// schemas without Go type, e.g. derived variants, can only be mapped by their name
+			byGoType[name] = schema
+			goTypes = append(goTypes, name)
+		}
+	}
+	sort.Strings(goTypes)
+
+	var names []string
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// map all components before comparing to resolve references between them
+	for _, name := range names {
+		v.schema = name
+		if goType, ok := expected[name][GoTypeExtension].(string); ok {
+			matches := matchGoTypes(goTypes, goType)
+			switch len(matches) {
+			case 0:
+				v.add("", "Go type %s of %s is not generated", goType, GoTypeExtension)
+			case 1:
+				v.goTypes[name] = matches[0]
+			default:
+				v.add("", "Go type %s of %s is ambiguous: %s", goType, GoTypeExtension, strings.Join(matches, ", "))
+			}
+			continue
+		}
+
+		if _, exists := generated[name]; exists {
+			if goType, ok := generated[name][GoTypeExtension].(string); ok {
+				v.goTypes[name] = goType
+			} else {
+				v.goTypes[name] = name
+			}
+		} else if matches := matchGoTypes(goTypes, name); len(matches) == 1 {
+			v.goTypes[name] = matches[0]
+		} else {
+			v.report.Unmapped = append(v.report.Unmapped, name)
+		}
+	}
+
+	for _, name := range names {
+		if goType, exists := v.goTypes[name]; exists {
+			v.schema, v.goType = name, goType
+			v.compare("", expected[name], byGoType[goType])
+		}
+	}
+
+	sort.SliceStable(v.report.Mismatches, func(i, j int) bool {
+		return v.report.Mismatches[i].Schema < v.report.Mismatches[j].Schema
+	})
+	return v.report, nil
+}
+
+// matchGoTypes returns the fully qualified Go types matching the given, possibly unqualified, Go type
+func matchGoTypes(goTypes []string, goType string) (matches []string) {
+	for _, candidate := range goTypes {
+		pkgQualified := candidate[strings.LastIndex(candidate, "/")+1:]
+		if candidate == goType || pkgQualified == goType || goTypeName(candidate) == goType {
+			matches = append(matches, candidate)
+		}
+	}
+
+	return
+}
+
+// goTypeName returns the name of a fully qualified Go type
+func goTypeName(goType string) string {
+	return goType[strings.LastIndex(goType, ".")+1:]
+}
+
+type verifier struct {
+	report *VerifyReport
+	// goTypes maps the component names to the fully qualified Go types
+	goTypes map[string]string
+	schema  string
+	goType  string
+}
+
+func (v *verifier) add(path string, message string, args ...interface{}) {
+	v.report.Mismatches = append(v.report.Mismatches, Mismatch{
+		Schema:  v.schema,
+		GoType:  v.goType,
+		Path:    path,
+		Message: fmt.Sprintf(message, args...),
+	})
+}
+
+// compare compares the expected schema of the document with the actual schema generated from Go
+func (v *verifier) compare(path string, expected, actual map[string]interface{}) {
+	expected, actual = unwrapRef(expected), unwrapRef(actual)
+
+	expectedRef, _ := expected["$ref"].(string)
+	actualRef, _ := actual["$ref"].(string)
+	switch {
+	case len(expectedRef) > 0 && len(actualRef) > 0:
+		name := jsonschema.RefName(expectedRef)
+		if goType, exists := v.goTypes[name]; exists {
+			name = goTypeName(goType)
+		}
+		if name != jsonschema.RefName(actualRef) {
+			v.add(path, "references %s but the Go type is %s", jsonschema.RefName(expectedRef), jsonschema.RefName(actualRef))
+		}
+		return
+	case len(expectedRef) > 0:
+		v.add(path, "references %s but the Go type is %s", jsonschema.RefName(expectedRef), describeType(actual))
+		return
+	case len(actualRef) > 0:
+		if _, typed := expected["type"]; typed {
+			v.add(path, "has type %s but the Go type is %s", describeType(expected), jsonschema.RefName(actualRef))
+		}
+		return
+	}
+
+	if _, typed := expected["type"]; !typed {
+		// an untyped schema accepts any value
+		return
+	}
+	if describeType(expected) != describeType(actual) {
+		v.add(path, "has type %s but the Go type is %s", describeType(expected), describeType(actual))
+		return
+	}
+	if expected["format"] != actual["format"] && !acceptsFormat(actual, expected["format"]) {
+		v.add(path, "has format %s but the Go type has format %s", valueOrNone(expected["format"]), valueOrNone(actual["format"]))
+	}
+
+	if items, ok := expected["items"].(map[string]interface{}); ok {
+		v.compare(path+"/items", items, jsonschema.AsObject(actual["items"]))
+	}
+	if additional, ok := expected["additionalProperties"].(map[string]interface{}); ok {
+		if actualAdditional, ok := actual["additionalProperties"].(map[string]interface{}); ok {
+			v.compare(path+"/additionalProperties", additional, actualAdditional)
+		}
+	}
+	v.compareProperties(path, expected, actual)
+}
+
+func (v *verifier) compareProperties(path string, expected, actual map[string]interface{}) {
+	expectedProps, actualProps := jsonschema.AsObject(expected["properties"]), jsonschema.AsObject(actual["properties"])
+	if expectedProps == nil && actualProps == nil {
+		return
+	}
+
+	var names []string
+	for name := range expectedProps {
+		names = append(names, name)
+	}
+	for name := range actualProps {
+		if _, exists := expectedProps[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	expectedRequired, actualRequired := jsonschema.AsStrings(expected["required"]), jsonschema.AsStrings(actual["required"])
+	for _, name := range names {
+		e, inExpected := expectedProps[name]
+		a, inActual := actualProps[name]
+		propertyPath := path + "/properties/" + jsonschema.EscapePointer(name)
+		switch {
+		case !inActual:
+			v.add(propertyPath, "property %s is not a field of the Go type", name)
+			continue
+		case !inExpected:
+			v.add(propertyPath, "field %s of the Go type is missing", name)
+			continue
+		}
+
+		if util.Contains(expectedRequired, name) && !util.Contains(actualRequired, name) {
+			v.add(propertyPath, "property %s is required but the field is not", name)
+		} else if !util.Contains(expectedRequired, name) && util.Contains(actualRequired, name) {
+			v.add(propertyPath, "property %s is optional but the field is required", name)
+		}
+		v.compare(propertyPath, jsonschema.AsObject(e), jsonschema.AsObject(a))
+	}
+}
+
+// numericFormats are the formats of OpenAPI for the numeric types
+var numericFormats = map[string][]string{
+	internal.IntegerType.String(): {"int32", "int64"},
+	internal.NumberType.String():  {"float", "double"},
+}
+
+// acceptsFormat reports whether the actual schema without format, e.g. of the Go type int, accepts the given
+// standard numeric format of its type
+func acceptsFormat(actual map[string]interface{}, format interface{}) bool {
+	name, ok := format.(string)
+	if _, formatted := actual["format"]; formatted || !ok {
+		return false
+	}
+
+	return util.Contains(numericFormats[describeType(actual)], name)
+}
+
+// unwrapRef returns the referenced schema of a reference wrapped in allOf, e.g. to add readOnly to a reference
+func unwrapRef(schema map[string]interface{}) map[string]interface{} {
+	if allOf := jsonschema.AsArray(schema["allOf"]); len(allOf) == 1 {
+		if ref, ok := jsonschema.AsObject(allOf[0])["$ref"]; ok {
+			return map[string]interface{}{"$ref": ref}
+		}
+	}
+
+	return schema
+}
+
+// describeType returns the type of the schema, e.g. string or [integer null]
+func describeType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		return fmt.Sprint(t)
+	case nil:
+		if len(schema) == 0 {
+			return "none"
+		}
+		return "any"
+	}
+
+	return fmt.Sprint(schema["type"])
+}
@@ -0,0 +1,111 @@
+package doc
+
+import (
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func Test_VerifyDocument(t *testing.T) {
+	generator := NewOpenapiGenerator(regexp.MustCompile("^TestItem$"), "json", WithGoTypeExtension())
+	schemas, err := generator.DocumentStruct("./testdata")
+	assert.NoError(t, err)
+	for _, schema := range schemas {
+		assert.Contains(t, schema.Extensions, GoTypeExtension)
+	}
+
+	document := `openapi: 3.0.3
+components:
+  schemas:
+    Item:
+      x-go-type: testdata.TestItem
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+        name:
+          type: integer
+        owner:
+          $ref: '#/components/schemas/Owner'
+        color:
+          type: string
+    Owner:
+      x-go-type: TestUnderlyingStruct
+      type: object
+      properties:
+        UnderlyingFieldB:
+          type: string
+        UnderlyingFieldC:
+          type: number
+          format: float
+        UnderlyingFieldD:
+          type: boolean
+    Error:
+      type: object
+    Missing:
+      x-go-type: example.com/model.Missing
+`
+	report, err := VerifyDocument([]byte(document), schemas)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Error"}, report.Unmapped)
+
+	var lines []string
+	for _, mismatch := range report.Mismatches {
+		lines = append(lines, mismatch.String())
+	}
+	assert.Equal(t, []string{
+		"Item/properties/color (github.com/mrahbar/gostruct2openapi/doc/testdata.TestItem): property color is not a field of the Go type",
+		"Item/properties/name (github.com/mrahbar/gostruct2openapi/doc/testdata.TestItem): property name is optional but the field is required",
+		"Item/properties/name (github.com/mrahbar/gostruct2openapi/doc/testdata.TestItem): has type integer but the Go type is string",
+		"Item/properties/secret (github.com/mrahbar/gostruct2openapi/doc/testdata.TestItem): field secret of the Go type is missing",
+		"Missing: Go type example.com/model.Missing of x-go-type is not generated",
+	}, lines)
+}
+
+func Test_VerifyDocument_NumericFormats(t *testing.T) {
+	// total and mean have no format like the Go type int
+	counter := spec.Schema{SchemaProps: spec.SchemaProps{
+		ID:   "Counter",
+		Type: spec.StringOrArray{"object"},
+		Properties: spec.SchemaProperties{
+			"count": *spec.Int64Property(),
+			"total": {SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"integer"}}},
+			"ratio": *spec.Float64Property(),
+			"mean":  {SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"number"}}},
+		},
+	}}
+	counter.AddExtension(GoTypeExtension, "example.com/model.Counter")
+
+	document := `openapi: 3.0.3
+components:
+  schemas:
+    Counter:
+      type: object
+      properties:
+        count:
+          type: integer
+          format: int32
+        total:
+          type: integer
+          format: int64
+        ratio:
+          type: number
+          format: float
+        mean:
+          type: number
+          format: double
+`
+	report, err := VerifyDocument([]byte(document), []spec.Schema{counter})
+	assert.NoError(t, err)
+
+	var lines []string
+	for _, mismatch := range report.Mismatches {
+		lines = append(lines, mismatch.String())
+	}
+	assert.Equal(t, []string{
+		"Counter/properties/count (example.com/model.Counter): has format \"int32\" but the Go type has format \"int64\"",
+		"Counter/properties/ratio (example.com/model.Counter): has format \"float\" but the Go type has format \"double\"",
+	}, lines)
+}
@@ -7,6 +7,7 @@ require (
 	github.com/go-openapi/spec v0.20.12
 	github.com/stretchr/testify v1.8.4
 	golang.org/x/tools v0.16.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -19,5 +20,4 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/mod v0.14.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )